@@ -0,0 +1,144 @@
+package bgcode
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const magic = "GCDE"
+
+// Block types
+const (
+	BlockFileMetadata    = 0
+	BlockGCode           = 1
+	BlockSlicerMetadata  = 2
+	BlockPrinterMetadata = 3
+	BlockPrintMetadata   = 4
+	BlockThumbnail       = 5
+)
+
+// Block compressions
+const (
+	CompressionNone    = 0
+	CompressionDeflate = 1
+)
+
+const checksumCRC32 = 1
+
+var (
+	// ErrNotBGCode is returned for files without the binary G-code magic
+	ErrNotBGCode = errors.New("not a binary G-code file")
+
+	// ErrUnsupported is returned for block data that cannot be decoded
+	ErrUnsupported = errors.New("unsupported binary G-code block")
+
+	// Stop ends Walk early without an error
+	Stop = errors.New("stop walking binary G-code blocks")
+)
+
+// Block is the header of a block
+type Block struct {
+	Type        uint16
+	Compression uint16
+	Size        int64  // stored size of the data
+	Params      []byte // format, width and height for thumbnails
+}
+
+// Walk calls fn for every block before the first G-code block; metadata and
+// thumbnail blocks always precede it. data reads the stored data of the
+// block, and whatever fn leaves unread is skipped.
+func Walk(r io.ReadSeeker, fn func(block Block, data io.Reader) error) error {
+	var header struct {
+		Magic        [4]byte
+		Version      uint32
+		ChecksumType uint16
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	if string(header.Magic[:]) != magic {
+		return ErrNotBGCode
+	}
+	checksumSize := int64(0)
+	if header.ChecksumType == checksumCRC32 {
+		checksumSize = 4
+	}
+
+	for {
+		var raw struct {
+			Type             uint16
+			Compression      uint16
+			UncompressedSize uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &raw); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		block := Block{Type: raw.Type, Compression: raw.Compression, Size: int64(raw.UncompressedSize)}
+		if block.Compression != CompressionNone {
+			var compressedSize uint32
+			if err := binary.Read(r, binary.LittleEndian, &compressedSize); err != nil {
+				return err
+			}
+			block.Size = int64(compressedSize)
+		}
+		if block.Type == BlockGCode {
+			return nil
+		}
+
+		block.Params = make([]byte, 2)
+		if block.Type == BlockThumbnail {
+			block.Params = make([]byte, 6)
+		}
+		if _, err := io.ReadFull(r, block.Params); err != nil {
+			return err
+		}
+
+		start, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		if err := fn(block, io.LimitReader(r, block.Size)); err != nil {
+			if err == Stop {
+				return nil
+			}
+			return err
+		}
+		if _, err := r.Seek(start+block.Size+checksumSize, io.SeekStart); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadData reads and decompresses the data of a block, up to limit bytes.
+// Heatshrink is only used for G-code blocks and is not supported.
+func ReadData(block Block, data io.Reader, limit int64) ([]byte, error) {
+	if block.Size > limit {
+		return nil, ErrUnsupported
+	}
+	stored, err := io.ReadAll(data)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(stored)) < block.Size {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	switch block.Compression {
+	case CompressionNone:
+		return stored, nil
+	case CompressionDeflate:
+		zr, err := zlib.NewReader(bytes.NewReader(stored))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(io.LimitReader(zr, limit))
+	}
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,97 @@
+package bgcode
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+// writeBlock appends a block with a CRC32 checksum placeholder
+func writeBlock(buf *bytes.Buffer, blockType, compression uint16, params, data []byte) {
+	stored := data
+	if compression == CompressionDeflate {
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		zw.Write(data)
+		zw.Close()
+		stored = compressed.Bytes()
+	}
+	binary.Write(buf, binary.LittleEndian, blockType)
+	binary.Write(buf, binary.LittleEndian, compression)
+	binary.Write(buf, binary.LittleEndian, uint32(len(data)))
+	if compression != CompressionNone {
+		binary.Write(buf, binary.LittleEndian, uint32(len(stored)))
+	}
+	buf.Write(params)
+	buf.Write(stored)
+	buf.Write([]byte{0, 0, 0, 0})
+}
+
+func testFile() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	binary.Write(&buf, binary.LittleEndian, uint32(1))
+	binary.Write(&buf, binary.LittleEndian, uint16(checksumCRC32))
+	writeBlock(&buf, BlockFileMetadata, CompressionDeflate, []byte{0, 0}, []byte("Producer=PrusaSlicer 2.7.1\n"))
+	writeBlock(&buf, BlockThumbnail, CompressionNone, []byte{0, 0, 16, 0, 16, 0}, []byte("png data"))
+	writeBlock(&buf, BlockGCode, CompressionNone, []byte{0, 0}, []byte("G28\n"))
+	writeBlock(&buf, BlockThumbnail, CompressionNone, []byte{0, 0, 16, 0, 16, 0}, []byte("after the G-code"))
+	return buf.Bytes()
+}
+
+func TestWalk(t *testing.T) {
+	var types []uint16
+	var contents []string
+	err := Walk(bytes.NewReader(testFile()), func(block Block, data io.Reader) error {
+		types = append(types, block.Type)
+		content, err := ReadData(block, data, 1<<10)
+		if err != nil {
+			return err
+		}
+		contents = append(contents, string(content))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantTypes := []uint16{BlockFileMetadata, BlockThumbnail}
+	wantContents := []string{"Producer=PrusaSlicer 2.7.1\n", "png data"}
+	if len(types) != len(wantTypes) {
+		t.Fatalf("walked blocks %v, want %v", types, wantTypes)
+	}
+	for i := range types {
+		if types[i] != wantTypes[i] || contents[i] != wantContents[i] {
+			t.Errorf("block %d = %d %q, want %d %q", i, types[i], contents[i], wantTypes[i], wantContents[i])
+		}
+	}
+}
+
+func TestWalkStop(t *testing.T) {
+	calls := 0
+	err := Walk(bytes.NewReader(testFile()), func(block Block, data io.Reader) error {
+		calls++
+		return Stop
+	})
+	if err != nil || calls != 1 {
+		t.Errorf("Walk = %v after %d calls, want nil after 1", err, calls)
+	}
+}
+
+func TestWalkNotBGCode(t *testing.T) {
+	err := Walk(bytes.NewReader([]byte("; generated by PrusaSlicer\nG28\n")), func(Block, io.Reader) error {
+		return nil
+	})
+	if err != ErrNotBGCode {
+		t.Errorf("Walk = %v, want ErrNotBGCode", err)
+	}
+}
+
+func TestReadDataLimit(t *testing.T) {
+	block := Block{Type: BlockFileMetadata, Size: 100}
+	if _, err := ReadData(block, bytes.NewReader(make([]byte, 100)), 10); err != ErrUnsupported {
+		t.Errorf("ReadData over the limit = %v, want ErrUnsupported", err)
+	}
+}
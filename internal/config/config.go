@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"qslicerpicker/internal/shellwords"
 )
 
 const (
@@ -13,32 +14,187 @@ const (
 )
 
 type Config struct {
-	Language      string          `json:"language"`
-	Slicers       []SlicerConfig  `json:"slicers"`
-	CustomSlicers []CustomSlicer  `json:"custom_slicers"`
+	Language      string         `json:"language"`
+	Slicers       []SlicerConfig `json:"slicers"`
+	CustomSlicers []CustomSlicer `json:"custom_slicers"`
+
+	// LaunchGraceSeconds is how long a launched slicer is watched for an
+	// early crash; 0 uses the default
+	LaunchGraceSeconds int `json:"launch_grace_seconds,omitempty"`
+
+	// Wrappers are commands every slicer is run under, outermost first,
+	// e.g. ["nice", "-n", "10"]; per-slicer wrappers run inside them
+	Wrappers []Arguments `json:"wrappers,omitempty"`
+
+	// Hooks run around every launch; per-slicer hooks run after these
+	Hooks Hooks `json:"hooks,omitempty"`
+
+	// HideUnsupportedSlicers hides slicers that cannot open the files from
+	// the selector instead of greying them out
+	HideUnsupportedSlicers bool `json:"hide_unsupported_slicers,omitempty"`
+
+	// OrderMode orders the selector by the manual order (default) or by
+	// launch history, see OrderRecent and OrderFrequent
+	OrderMode string `json:"order_mode,omitempty"`
+
+	// SeparateVersions lists every installed version of a default slicer
+	// as its own entry, with the ID "<id>@<version>"
+	SeparateVersions bool `json:"separate_versions,omitempty"`
+
+	// CountdownSeconds counts down on the pre-selected slicer in the
+	// selector and opens it unless the user interacts; 0 disables it
+	CountdownSeconds int `json:"countdown_seconds,omitempty"`
+
+	// ExtensionCountdowns override CountdownSeconds by file extension,
+	// e.g. {"gcode": 0}
+	ExtensionCountdowns map[string]int `json:"extension_countdowns,omitempty"`
+
+	// Associations are slicers remembered from the selector; they are
+	// checked before rules
+	Associations []Association `json:"associations,omitempty"`
+
+	// Rules pick a slicer without showing the selector; the first rule
+	// matching every opened file wins
+	Rules []Rule `json:"rules,omitempty"`
 }
 
 type SlicerConfig struct {
-	ID         string   `json:"id"`
-	Enabled    bool     `json:"enabled"`
-	Order      int      `json:"order"`
-	CustomPath string   `json:"custom_path,omitempty"`
-	Arguments  []string `json:"arguments,omitempty"`
-	WorkingDir string   `json:"working_dir,omitempty"`
+	ID              string      `json:"id"`
+	Enabled         bool        `json:"enabled"`
+	Order           int         `json:"order"`
+	CustomPath      string      `json:"custom_path,omitempty"`
+	Arguments       Arguments   `json:"arguments,omitempty"`
+	WorkingDir      string      `json:"working_dir,omitempty"`
+	ViewerArguments Arguments   `json:"viewer_arguments,omitempty"` // used when every file is G-code; nil keeps the default, empty turns it off
+	Formats         []string    `json:"formats,omitempty"`          // extensions the slicer opens; nil keeps the default, empty opens any file
+	Icon            string      `json:"icon,omitempty"`             // image shown in the lists
+	FlatpakID       string      `json:"flatpak_id,omitempty"`
+	SingleFile      bool        `json:"single_file,omitempty"`
+	Environment     []EnvVar    `json:"environment,omitempty"`
+	Wrappers        []Arguments `json:"wrappers,omitempty"`
+	Hooks           Hooks       `json:"hooks,omitempty"`
+}
+
+// MarshalJSON writes empty viewer arguments and formats as [], so that
+// replacing the defaults with nothing survives saving, and leaves out unset
+// ones
+func (sc SlicerConfig) MarshalJSON() ([]byte, error) {
+	type plain SlicerConfig
+	out := struct {
+		plain
+		ViewerArguments *Arguments `json:"viewer_arguments,omitempty"`
+		Formats         *[]string  `json:"formats,omitempty"`
+	}{plain: plain(sc)}
+	if sc.ViewerArguments != nil {
+		out.ViewerArguments = &sc.ViewerArguments
+	}
+	if sc.Formats != nil {
+		out.Formats = &sc.Formats
+	}
+	return json.Marshal(out)
 }
 
 type CustomSlicer struct {
-	Name        string   `json:"name"`
-	Path        string   `json:"path"`
-	Arguments   []string `json:"arguments,omitempty"`
-	WorkingDir  string   `json:"working_dir,omitempty"`
-	Enabled     bool     `json:"enabled"`
-	Order       int      `json:"order"`
+	Name            string      `json:"name"`
+	Path            string      `json:"path"`
+	Arguments       Arguments   `json:"arguments,omitempty"`
+	WorkingDir      string      `json:"working_dir,omitempty"`
+	ViewerArguments Arguments   `json:"viewer_arguments,omitempty"` // used when every file is G-code
+	Formats         []string    `json:"formats,omitempty"`          // extensions the slicer opens
+	Icon            string      `json:"icon,omitempty"`             // image shown in the lists
+	FlatpakID       string      `json:"flatpak_id,omitempty"`
+	SingleFile      bool        `json:"single_file,omitempty"`
+	Environment     []EnvVar    `json:"environment,omitempty"`
+	Wrappers        []Arguments `json:"wrappers,omitempty"`
+	Hooks           Hooks       `json:"hooks,omitempty"`
+	Enabled         bool        `json:"enabled"`
+	Order           int         `json:"order"`
+}
+
+// Hooks are commands run around a slicer launch. They receive the slicer and
+// the files in QSLICERPICKER_* environment variables.
+type Hooks struct {
+	PreLaunch []Arguments `json:"pre_launch,omitempty"`
+	PostExit  []Arguments `json:"post_exit,omitempty"`
+}
+
+// Selector order modes
+const (
+	OrderManual   = "manual"   // the order set in the settings
+	OrderRecent   = "recent"   // most recently used first
+	OrderFrequent = "frequent" // most often used first
+)
+
+// Association is a slicer remembered for a file extension or a directory
+type Association struct {
+	Extension string `json:"extension,omitempty"` // without the dot
+	Directory string `json:"directory,omitempty"` // includes subdirectories
+	Slicer    string `json:"slicer"`
+}
+
+// RuleAsk is the rule target that always shows the selector
+const RuleAsk = "ask"
+
+// Rule selects a slicer for files that match all of its conditions. Empty
+// conditions match any file.
+type Rule struct {
+	Name       string   `json:"name,omitempty"`
+	Disabled   bool     `json:"disabled,omitempty"`
+	Extensions []string `json:"extensions,omitempty"` // without the dot, e.g. ["bgcode", "gcode"]
+	Glob       string   `json:"glob,omitempty"`       // file name pattern, e.g. "*_pla.3mf"
+	Directory  string   `json:"directory,omitempty"`  // the file is in this directory or below it
+	MinSize    int64    `json:"min_size,omitempty"`   // bytes
+	MaxSize    int64    `json:"max_size,omitempty"`   // bytes
+	MIMEType   string   `json:"mime_type,omitempty"`  // e.g. "model/3mf" or "model/*"
+	Slicer     string   `json:"slicer"`               // slicer ID, or RuleAsk
+}
+
+// Environment override modes
+const (
+	EnvSet     = "set"     // set the variable (default)
+	EnvUnset   = "unset"   // remove the variable
+	EnvPrepend = "prepend" // prepend to a path list such as PATH or LD_LIBRARY_PATH
+)
+
+// EnvVar is an environment variable override applied when launching a slicer
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+	Mode  string `json:"mode,omitempty"`
+}
+
+// Arguments is a command line stored as a JSON array. A plain string is also
+// accepted and split using shell quoting rules, e.g. "--datadir 'My Profiles'".
+type Arguments []string
+
+// UnmarshalJSON accepts either an array of arguments or a shell-style string.
+// An empty array or string gives empty, not nil, arguments.
+func (a *Arguments) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var line string
+	if err := json.Unmarshal(data, &line); err == nil {
+		args, err := shellwords.Split(line)
+		if err != nil {
+			return fmt.Errorf("invalid arguments %q: %w", line, err)
+		}
+		*a = append(Arguments{}, args...)
+		return nil
+	}
+
+	var args []string
+	if err := json.Unmarshal(data, &args); err != nil {
+		return err
+	}
+	*a = args
+	return nil
 }
 
 var (
 	configInstance *Config
 	configPath     string
+	loadErr        error
 )
 
 func init() {
@@ -64,34 +220,50 @@ func GetConfig() *Config {
 	return configInstance
 }
 
-// LoadConfig loads the configuration from file, or returns default if file doesn't exist
+// LoadConfig loads the configuration from file, or returns default if file
+// doesn't exist. If the file cannot be read or parsed, the default is returned
+// as well and the error is kept for LoadError.
 func LoadConfig() *Config {
-	config := &Config{
-		Language:      "en",
-		Slicers:       []SlicerConfig{},
-		CustomSlicers: []CustomSlicer{},
+	newDefault := func() *Config {
+		return &Config{
+			Language:      "en",
+			Slicers:       []SlicerConfig{},
+			CustomSlicers: []CustomSlicer{},
+		}
 	}
+	loadErr = nil
 
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return config
+		if !os.IsNotExist(err) {
+			loadErr = fmt.Errorf("failed to read config file: %w", err)
 		}
-		// If there's an error reading, return default config
-		return config
+		return newDefault()
 	}
 
+	config := newDefault()
 	if err := json.Unmarshal(data, config); err != nil {
-		// If there's an error parsing, return default config
-		return config
+		// A partially decoded config is not used
+		loadErr = fmt.Errorf("failed to parse %s: %w", configPath, err)
+		return newDefault()
 	}
 
 	return config
 }
 
+// LoadError returns why the config file could not be loaded, or nil. While
+// it is set, SaveConfig does not overwrite the file.
+func LoadError() error {
+	GetConfig()
+	return loadErr
+}
+
 // SaveConfig saves the current configuration to file
 func SaveConfig() error {
 	config := GetConfig()
+	if loadErr != nil {
+		return fmt.Errorf("not saving the configuration over %s: %w", configPath, loadErr)
+	}
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useConfigFile points the package at a temporary config file
+func useConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldPath, oldInstance, oldErr := configPath, configInstance, loadErr
+	configPath, configInstance, loadErr = path, nil, nil
+	t.Cleanup(func() {
+		configPath, configInstance, loadErr = oldPath, oldInstance, oldErr
+	})
+	return path
+}
+
+func TestArgumentsString(t *testing.T) {
+	useConfigFile(t, `{"language": "de", "slicers": [{"id": "cura", "arguments": "--datadir 'My Profiles'"}]}`)
+	cfg := GetConfig()
+	if err := LoadError(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"--datadir", "My Profiles"}
+	if got := cfg.Slicers[0].Arguments; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("arguments = %q, want %q", got, want)
+	}
+}
+
+func TestUnparsableConfigIsNotOverwritten(t *testing.T) {
+	content := `{"language": "de", "slicers": [{"id": "cura", "arguments": "--name 'unbalanced"}]}`
+	path := useConfigFile(t, content)
+
+	cfg := GetConfig()
+	if LoadError() == nil {
+		t.Fatal("LoadError() = nil for an unbalanced quote")
+	}
+	if cfg.Language != "en" || len(cfg.Slicers) != 0 {
+		t.Errorf("partially decoded config used: %+v", cfg)
+	}
+
+	cfg.Language = "fr"
+	if err := SaveConfig(); err == nil {
+		t.Error("SaveConfig() overwrote a config that failed to parse")
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("config file changed to %s", data)
+	}
+}
+
+func TestMissingConfig(t *testing.T) {
+	path := useConfigFile(t, "")
+	GetConfig()
+	if err := LoadError(); err != nil {
+		t.Fatalf("LoadError() = %v for a missing file", err)
+	}
+	if err := SaveConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEmptyViewerArgumentsAreKept(t *testing.T) {
+	useConfigFile(t, `{"slicers": [{"id": "prusaslicer", "viewer_arguments": []}, {"id": "superslicer"}]}`)
+	cfg := GetConfig()
+	if err := LoadError(); err != nil {
+		t.Fatal(err)
+	}
+	if args := cfg.Slicers[0].ViewerArguments; args == nil || len(args) != 0 {
+		t.Fatalf("viewer arguments = %#v, want empty", args)
+	}
+	if args := cfg.Slicers[1].ViewerArguments; args != nil {
+		t.Fatalf("viewer arguments = %#v, want nil", args)
+	}
+
+	if err := SaveConfig(); err != nil {
+		t.Fatal(err)
+	}
+	configInstance = nil
+	cfg = GetConfig()
+	if args := cfg.Slicers[0].ViewerArguments; args == nil || len(args) != 0 {
+		t.Errorf("saved viewer arguments = %#v, want empty", args)
+	}
+	if args := cfg.Slicers[1].ViewerArguments; args != nil {
+		t.Errorf("saved viewer arguments = %#v, want nil", args)
+	}
+}
+
+func TestEmptyFormatsAreKept(t *testing.T) {
+	useConfigFile(t, `{"slicers": [{"id": "kisslicer", "formats": []}, {"id": "cura"}]}`)
+	GetConfig()
+	if err := SaveConfig(); err != nil {
+		t.Fatal(err)
+	}
+	configInstance = nil
+	cfg := GetConfig()
+	if formats := cfg.Slicers[0].Formats; formats == nil || len(formats) != 0 {
+		t.Errorf("saved formats = %#v, want empty", formats)
+	}
+	if formats := cfg.Slicers[1].Formats; formats != nil {
+		t.Errorf("saved formats = %#v, want nil", formats)
+	}
+}
@@ -0,0 +1,188 @@
+package desktopentry
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"qslicerpicker/internal/shellwords"
+	"strings"
+)
+
+// Entry holds the keys of a .desktop file's [Desktop Entry] group that the
+// picker uses; localized keys are ignored
+type Entry struct {
+	Path           string // the .desktop file
+	Name           string
+	Exec           string
+	TryExec        string
+	Icon           string
+	MimeType       []string
+	StartupWMClass string
+	Hidden         bool // the entry is deleted and must be ignored
+	NoDisplay      bool // the entry is kept out of menus but still opens files
+
+	// AppImageVersion is X-AppImage-Version, written by AppImage
+	// integration tools
+	AppImageVersion string
+}
+
+// Parse reads a .desktop file
+func Parse(path string) (*Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entry := &Entry{Path: path}
+	inGroup := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inGroup = line == "[Desktop Entry]"
+			continue
+		}
+		if !inGroup {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = unescape(strings.TrimSpace(value))
+		switch strings.TrimSpace(key) {
+		case "Name":
+			entry.Name = value
+		case "Exec":
+			entry.Exec = value
+		case "TryExec":
+			entry.TryExec = value
+		case "Icon":
+			entry.Icon = value
+		case "StartupWMClass":
+			entry.StartupWMClass = value
+		case "X-AppImage-Version":
+			entry.AppImageVersion = value
+		case "MimeType":
+			for _, mimeType := range strings.Split(value, ";") {
+				if mimeType != "" {
+					entry.MimeType = append(entry.MimeType, mimeType)
+				}
+			}
+		case "Hidden":
+			entry.Hidden = value == "true"
+		case "NoDisplay":
+			entry.NoDisplay = value == "true"
+		}
+	}
+	return entry, scanner.Err()
+}
+
+// unescape resolves the escape sequences of string values
+func unescape(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	return strings.NewReplacer(`\s`, " ", `\n`, "\n", `\t`, "\t", `\r`, "\r", `\\`, `\`).Replace(value)
+}
+
+// DataDirs returns the XDG data directories, most important first, followed
+// by the directories Flatpak exports applications and icons to
+func DataDirs() []string {
+	var dirs []string
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		dirs = append(dirs, dataHome)
+	} else if homeDir, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(homeDir, ".local", "share"))
+	}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(homeDir, ".local", "share", "flatpak", "exports", "share"))
+	}
+	dirs = append(dirs, "/var/lib/flatpak/exports/share")
+
+	// XDG_DATA_DIRS often lists the Flatpak exports already
+	seen := make(map[string]bool, len(dirs))
+	unique := dirs[:0]
+	for _, dir := range dirs {
+		if !seen[filepath.Clean(dir)] {
+			seen[filepath.Clean(dir)] = true
+			unique = append(unique, dir)
+		}
+	}
+	return unique
+}
+
+// All returns the application entries in the data directories, including
+// their subdirectories. An entry shadows entries with the same desktop file
+// ID in less important directories.
+func All() []*Entry {
+	var entries []*Entry
+	seen := make(map[string]bool)
+	for _, dir := range DataDirs() {
+		applications := filepath.Join(dir, "applications")
+		filepath.WalkDir(applications, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(path) != ".desktop" {
+				return nil
+			}
+			id := fileID(applications, path)
+			if seen[id] {
+				return nil
+			}
+			seen[id] = true
+			if entry, err := Parse(path); err == nil {
+				entries = append(entries, entry)
+			}
+			return nil
+		})
+	}
+	return entries
+}
+
+// fileID returns the desktop file ID of an entry below an applications
+// directory: its relative path with slashes replaced by dashes, e.g.
+// "kde-org.kde.krita.desktop" for kde/org.kde.krita.desktop
+func fileID(applications, path string) string {
+	rel, err := filepath.Rel(applications, path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", "-")
+}
+
+// Args splits the Exec line into arguments, keeping field codes such as %f
+func (e *Entry) Args() ([]string, error) {
+	return shellwords.Split(e.Exec)
+}
+
+// Command returns the executable of the Exec line, skipping an
+// "env VAR=value" prefix, or "" if there is none
+func (e *Entry) Command() string {
+	args, err := e.Args()
+	if err != nil {
+		return ""
+	}
+	for i, arg := range args {
+		if (i == 0 && arg == "env") || (i > 0 && args[0] == "env" && strings.Contains(arg, "=")) {
+			continue
+		}
+		return arg
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+package desktopentry
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeEntry writes a .desktop file below dir
+func writeEntry(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParse(t *testing.T) {
+	path := writeEntry(t, t.TempDir(), "PrusaSlicer.desktop", `# Written by hand
+[Desktop Action Gcodeviewer]
+Name=G-code Viewer
+Exec=prusa-slicer --gcodeviewer %F
+
+[Desktop Entry]
+Type=Application
+Name=PrusaSlicer
+Name[de]=PrusaSlicer (Deutsch)
+Comment=Slicer\sfor\t3D printers
+Exec=env GDK_BACKEND=x11 /opt/Prusa\sSlicer/prusa-slicer --datadir C:\\profiles %F
+TryExec = /opt/Prusa Slicer/prusa-slicer
+Icon=PrusaSlicer
+MimeType=model/stl;application/vnd.ms-3mfdocument;;model/3mf;
+StartupWMClass=prusa-slicer
+NoDisplay=true
+X-AppImage-Version=2.7.4
+Actions=Gcodeviewer;
+
+[Desktop Action Other]
+Name=Other
+Hidden=true
+Exec=other
+`)
+	entry, err := Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Entry{
+		Path:            path,
+		Name:            "PrusaSlicer",
+		Exec:            `env GDK_BACKEND=x11 /opt/Prusa Slicer/prusa-slicer --datadir C:\profiles %F`,
+		TryExec:         "/opt/Prusa Slicer/prusa-slicer",
+		Icon:            "PrusaSlicer",
+		MimeType:        []string{"model/stl", "application/vnd.ms-3mfdocument", "model/3mf"},
+		StartupWMClass:  "prusa-slicer",
+		NoDisplay:       true,
+		AppImageVersion: "2.7.4",
+	}
+	if !reflect.DeepEqual(entry, want) {
+		t.Errorf("Parse() = %+v\nwant %+v", entry, want)
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	tests := map[string]string{
+		`plain`:          "plain",
+		`a\sb`:           "a b",
+		`line\nbreak`:    "line\nbreak",
+		`tab\there`:      "tab\there",
+		`back\\slash`:    `back\slash`,
+		`not\\space`:     `not\space`,
+		`carriage\rend`:  "carriage\rend",
+		`C:\\Program\s1`: `C:\Program 1`,
+	}
+	for value, want := range tests {
+		if got := unescape(value); got != want {
+			t.Errorf("unescape(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestAll(t *testing.T) {
+	home := t.TempDir()
+	dataHome := filepath.Join(home, "data")
+	system := filepath.Join(home, "system")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_DATA_DIRS", system)
+
+	entry := func(name string, extra string) string {
+		return "[Desktop Entry]\nType=Application\nName=" + name + "\nExec=" + name + " %F\n" + extra
+	}
+	writeEntry(t, system, "applications/cura.desktop", entry("Cura", ""))
+	writeEntry(t, system, "applications/vendor/orca.desktop", entry("OrcaSlicer", ""))
+	writeEntry(t, system, "applications/vendor-bambu.desktop", entry("Bambu Studio", ""))
+	writeEntry(t, system, "applications/viewer.desktop", entry("Viewer", "NoDisplay=true\n"))
+	writeEntry(t, system, "applications/readme.txt", "not an entry")
+	// The user's entries shadow the system ones with the same desktop file ID
+	writeEntry(t, dataHome, "applications/vendor-orca.desktop", entry("OrcaSlicer (user)", ""))
+	writeEntry(t, dataHome, "applications/cura.desktop", entry("Cura", "Hidden=true\n"))
+
+	var names []string
+	for _, e := range All() {
+		// Skip the system's Flatpak exports
+		if !strings.HasPrefix(e.Path, home) {
+			continue
+		}
+		name := e.Name
+		if e.Hidden {
+			name += " (hidden)"
+		}
+		if e.NoDisplay {
+			name += " (no display)"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	want := []string{"Bambu Studio", "Cura (hidden)", "OrcaSlicer (user)", "Viewer (no display)"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("All() = %q, want %q", names, want)
+	}
+}
+
+func TestFileID(t *testing.T) {
+	applications := filepath.Join("usr", "share", "applications")
+	tests := map[string]string{
+		"cura.desktop":                   "cura.desktop",
+		"kde/org.kde.krita.desktop":      "kde-org.kde.krita.desktop",
+		"a/b/com.example.Viewer.desktop": "a-b-com.example.Viewer.desktop",
+	}
+	for rel, want := range tests {
+		if got := fileID(applications, filepath.Join(applications, filepath.FromSlash(rel))); got != want {
+			t.Errorf("fileID(%q) = %q, want %q", rel, got, want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package fetch
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Schemes are the "Open in slicer" URL schemes used by model websites
+var Schemes = []string{"prusaslicer", "orcaslicer", "bambustudio", "bambustudioopen", "cura"}
+
+const (
+	maxDownloadSize = 2 << 30 // 2 GiB
+	cacheMaxAge     = 30 * 24 * time.Hour
+	userAgent       = "QSlicerPicker"
+)
+
+// Fetcher downloads models into a cache directory
+type Fetcher struct {
+	Client   *http.Client
+	CacheDir string
+}
+
+// NewFetcher returns a Fetcher using the user cache directory
+func NewFetcher() (*Fetcher, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache directory: %w", err)
+	}
+	return &Fetcher{
+		Client:   &http.Client{Timeout: 10 * time.Minute},
+		CacheDir: filepath.Join(cacheDir, "qslicerpicker", "downloads"),
+	}, nil
+}
+
+// IsSlicerURL reports whether arg is a slicer URL scheme link
+func IsSlicerURL(arg string) bool {
+	scheme, _, ok := strings.Cut(arg, ":")
+	if !ok {
+		return false
+	}
+	for _, s := range Schemes {
+		if strings.EqualFold(scheme, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSlicerURL extracts the model download URL embedded in a slicer URL,
+// either as a "file" query parameter (prusaslicer://open?file=https%3A...)
+// or as the escaped remainder of the link (bambustudioopen://https%3A...)
+func ParseSlicerURL(raw string) (*url.URL, error) {
+	if !IsSlicerURL(raw) {
+		return nil, fmt.Errorf("not a slicer URL: %s", raw)
+	}
+
+	var candidate string
+	if u, err := url.Parse(raw); err == nil {
+		candidate = u.Query().Get("file")
+	}
+	if candidate == "" {
+		_, rest, _ := strings.Cut(raw, ":")
+		candidate = strings.TrimPrefix(rest, "//")
+	}
+
+	// Some sites escape the embedded URL twice
+	for i := 0; i < 3 && !strings.HasPrefix(candidate, "http://") && !strings.HasPrefix(candidate, "https://"); i++ {
+		unescaped, err := url.QueryUnescape(candidate)
+		if err != nil || unescaped == candidate {
+			break
+		}
+		candidate = unescaped
+	}
+
+	modelURL, err := url.Parse(candidate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model URL in %s: %w", raw, err)
+	}
+	if (modelURL.Scheme != "http" && modelURL.Scheme != "https") || modelURL.Host == "" {
+		return nil, fmt.Errorf("no model URL in %s", raw)
+	}
+	return modelURL, nil
+}
+
+// Resolve turns a command line argument into a local path: slicer URLs and
+// http(s) URLs are downloaded, file:// URLs are converted, and anything else
+// is returned unchanged
+func (f *Fetcher) Resolve(ctx context.Context, arg string) (string, error) {
+	switch {
+	case IsSlicerURL(arg):
+		modelURL, err := ParseSlicerURL(arg)
+		if err != nil {
+			return "", err
+		}
+		return f.Download(ctx, modelURL)
+	case strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://"):
+		modelURL, err := url.Parse(arg)
+		if err != nil {
+			return "", err
+		}
+		return f.Download(ctx, modelURL)
+	case strings.HasPrefix(arg, "file://"):
+		u, err := url.Parse(arg)
+		if err != nil {
+			return "", err
+		}
+		filePath := u.Path
+		// file:///C:/model.stl has the drive letter after the slash
+		if runtime.GOOS == "windows" && len(filePath) > 2 && filePath[0] == '/' && filePath[2] == ':' {
+			filePath = filePath[1:]
+		}
+		return filepath.FromSlash(filePath), nil
+	}
+	return arg, nil
+}
+
+// Download fetches a model into the cache directory and returns its path.
+// Each URL gets its own subdirectory so that equally named models from
+// different sources do not overwrite each other. A model that is already
+// cached is only downloaded again when the server reports a change.
+func (f *Fetcher) Download(ctx context.Context, modelURL *url.URL) (string, error) {
+	f.pruneCache()
+
+	sum := sha1.Sum([]byte(modelURL.String()))
+	dir := filepath.Join(f.CacheDir, hex.EncodeToString(sum[:])[:12])
+	cached, cachedInfo := cachedFile(dir)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, modelURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if cached != "" {
+		req.Header.Set("If-Modified-Since", cachedInfo.ModTime().UTC().Format(http.TimeFormat))
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", modelURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != "" {
+		// Keep the cache entry from being pruned while it is in use
+		now := time.Now()
+		os.Chtimes(dir, now, now)
+		return cached, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", modelURL, resp.Status)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	// Download next to the target and rename, so that an interrupted
+	// download never looks like a complete model
+	tmp, err := os.CreateTemp(dir, ".download-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create download file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, io.LimitReader(resp.Body, maxDownloadSize+1))
+	closeErr := tmp.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", modelURL, err)
+	}
+	if closeErr != nil {
+		return "", fmt.Errorf("failed to write download: %w", closeErr)
+	}
+	if written > maxDownloadSize {
+		return "", fmt.Errorf("download %s exceeds %d bytes", modelURL, int64(maxDownloadSize))
+	}
+
+	target := filepath.Join(dir, fileName(resp, modelURL))
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", fmt.Errorf("failed to store download: %w", err)
+	}
+	// The server may have renamed the model since it was cached
+	if cached != "" && cached != target {
+		os.Remove(cached)
+	}
+	return target, nil
+}
+
+// cachedFile returns the completed download in a cache subdirectory, if any
+func cachedFile(dir string) (string, os.FileInfo) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil
+	}
+	for _, entry := range entries {
+		// Unfinished downloads start with a dot
+		if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") {
+			if info, err := entry.Info(); err == nil {
+				return filepath.Join(dir, entry.Name()), info
+			}
+		}
+	}
+	return "", nil
+}
+
+// fileName picks a safe file name from Content-Disposition or the URL path
+func fileName(resp *http.Response, modelURL *url.URL) string {
+	name := ""
+	if disposition := resp.Header.Get("Content-Disposition"); disposition != "" {
+		if _, params, err := mime.ParseMediaType(disposition); err == nil {
+			name = params["filename"]
+		}
+	}
+	if name == "" {
+		name = path.Base(modelURL.Path)
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(name, ".")
+	if name == "" {
+		name = "model"
+	}
+	return name
+}
+
+// pruneCache removes downloads older than cacheMaxAge
+func (f *Fetcher) pruneCache() {
+	entries, err := os.ReadDir(f.CacheDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > cacheMaxAge {
+			os.RemoveAll(filepath.Join(f.CacheDir, entry.Name()))
+		}
+	}
+}
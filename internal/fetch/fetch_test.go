@@ -0,0 +1,157 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const modelData = "solid cube\nendsolid cube\n"
+
+// modelServer serves modelData with a fixed modification time, so that
+// conditional requests get 304 Not Modified
+func modelServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	var downloads int32
+	modified := time.Now().Add(-time.Hour)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/models/cube.stl", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != userAgent {
+			t.Errorf("User-Agent = %q, want %q", r.Header.Get("User-Agent"), userAgent)
+		}
+		if r.Header.Get("If-Modified-Since") == "" {
+			atomic.AddInt32(&downloads, 1)
+		}
+		http.ServeContent(w, r, "cube.stl", modified, strings.NewReader(modelData))
+	})
+	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="../Benchy.3mf"`)
+		w.Write([]byte(modelData))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+func newTestFetcher(t *testing.T, server *httptest.Server) *Fetcher {
+	return &Fetcher{Client: server.Client(), CacheDir: t.TempDir()}
+}
+
+func TestResolve(t *testing.T) {
+	server, _ := modelServer(t)
+	f := newTestFetcher(t, server)
+	modelURL := server.URL + "/models/cube.stl"
+
+	for _, arg := range []string{
+		modelURL,
+		"prusaslicer://open?file=" + url.QueryEscape(modelURL),
+		"bambustudioopen://" + url.QueryEscape(url.QueryEscape(modelURL)),
+	} {
+		path, err := f.Resolve(context.Background(), arg)
+		if err != nil {
+			t.Errorf("Resolve(%q): %v", arg, err)
+			continue
+		}
+		if filepath.Base(path) != "cube.stl" || !strings.HasPrefix(path, f.CacheDir) {
+			t.Errorf("Resolve(%q) = %q, want cube.stl in %s", arg, path, f.CacheDir)
+		}
+	}
+
+	if path, err := f.Resolve(context.Background(), "/tmp/model.stl"); err != nil || path != "/tmp/model.stl" {
+		t.Errorf("Resolve of a path = %q, %v", path, err)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	server, downloads := modelServer(t)
+	f := newTestFetcher(t, server)
+	modelURL, _ := url.Parse(server.URL + "/models/cube.stl")
+
+	path, err := f.Download(context.Background(), modelURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != modelData {
+		t.Fatalf("downloaded %q, %v; want %q", data, err, modelData)
+	}
+
+	// The temporary file was renamed, not left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 || entries[0].Name() != "cube.stl" {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("download directory holds %q, want only cube.stl", names)
+	}
+
+	// A second download of an unchanged model uses the cached file
+	again, err := f.Download(context.Background(), modelURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != path {
+		t.Errorf("cached download = %q, want %q", again, path)
+	}
+	if n := atomic.LoadInt32(downloads); n != 1 {
+		t.Errorf("model downloaded %d times, want 1", n)
+	}
+}
+
+func TestDownloadFileName(t *testing.T) {
+	server, _ := modelServer(t)
+	f := newTestFetcher(t, server)
+	modelURL, _ := url.Parse(server.URL + "/download")
+
+	path, err := f.Download(context.Background(), modelURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := filepath.Base(path); name != "_Benchy.3mf" {
+		t.Errorf("file name = %q, want _Benchy.3mf", name)
+	}
+}
+
+func TestDownloadError(t *testing.T) {
+	server, _ := modelServer(t)
+	f := newTestFetcher(t, server)
+	modelURL, _ := url.Parse(server.URL + "/missing.stl")
+
+	if path, err := f.Download(context.Background(), modelURL); err == nil {
+		t.Errorf("Download of a missing model = %q, want an error", path)
+	}
+	if entries, _ := filepath.Glob(filepath.Join(f.CacheDir, "*", "*")); len(entries) != 0 {
+		t.Errorf("failed download left %q", entries)
+	}
+}
+
+func TestParseSlicerURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"prusaslicer://open?file=https%3A%2F%2Fexample.com%2Fa.stl", "https://example.com/a.stl"},
+		{"orcaslicer://open?file=https%253A%252F%252Fexample.com%252Fa.3mf", "https://example.com/a.3mf"},
+		{"bambustudioopen://https%3A%2F%2Fexample.com%2Fb.3mf", "https://example.com/b.3mf"},
+		{"cura://open?file=ftp%3A%2F%2Fexample.com%2Fa.stl", ""},
+		{"prusaslicer://open?file=file%3A%2F%2F%2Fetc%2Fpasswd", ""},
+		{"https://example.com/a.stl", ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseSlicerURL(tt.raw)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("ParseSlicerURL(%q) = %q, want an error", tt.raw, got)
+		case tt.want != "" && (err != nil || got.String() != tt.want):
+			t.Errorf("ParseSlicerURL(%q) = %v, %v; want %q", tt.raw, got, err, tt.want)
+		}
+	}
+}
@@ -1,49 +1,424 @@
 package filehandler
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"qslicerpicker/internal/config"
+	"qslicerpicker/internal/fetch"
+	"qslicerpicker/internal/history"
+	"qslicerpicker/internal/i18n"
+	"qslicerpicker/internal/inspect"
+	"qslicerpicker/internal/instance"
+	"qslicerpicker/internal/project"
+	"qslicerpicker/internal/rules"
 	"qslicerpicker/internal/slicer"
 	"qslicerpicker/internal/ui"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 )
 
-// HandleFile handles a file that should be opened with a slicer
-func HandleFile(filePath string) {
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "File not found: %s\n", filePath)
-		os.Exit(1)
+// Options change how files are handled
+type Options struct {
+	// Pick always shows the selector, ignoring remembered slicers and rules
+	Pick bool
+}
+
+// HandleFile handles files that should be opened with a slicer
+func HandleFile(filePaths ...string) {
+	HandleFileWithOptions(Options{}, filePaths...)
+}
+
+// HandleFileWithOptions handles files that should be opened with a slicer
+func HandleFileWithOptions(opts Options, filePaths ...string) {
+	if !needsDownload(filePaths) {
+		filePaths, err := resolveURLs(context.Background(), filePaths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		handleFiles(opts, nil, filePaths)
+		return
+	}
+
+	// Models are downloaded in a window of the app that shows the selector
+	// afterwards
+	fyneApp := app.NewWithID("com.qslicerpicker.selector")
+	ui.RunInBackground(fyneApp, func() {
+		label := strings.Join(filePaths, "\n")
+		files, err := ui.ShowDownload(fyneApp, label, func(ctx context.Context) ([]string, error) {
+			files, err := resolveURLs(ctx, filePaths)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			return files, err
+		})
+		if errors.Is(err, context.Canceled) {
+			os.Exit(0)
+		}
+		if err != nil {
+			os.Exit(1)
+		}
+		handleFiles(opts, fyneApp, files)
+	})
+}
+
+// handleFiles handles local files; the app is created if fyneApp is nil
+func handleFiles(opts Options, fyneApp fyne.App, filePaths []string) {
+	files := make([]string, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		// Check if file exists
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "File not found: %s\n", filePath)
+			os.Exit(1)
+		}
+		// Slicers may run in another working directory
+		if absPath, err := filepath.Abs(filePath); err == nil {
+			filePath = absPath
+		}
+		files = append(files, filePath)
 	}
 
 	// Initialize config and i18n
-	config.GetConfig()
+	cfg := config.GetConfig()
 	// i18n is initialized automatically via init()
 
-	// Create a minimal app for the dialog
-	fyneApp := app.NewWithID("com.qslicerpicker.selector")
+	// A .qslicerpicker.json next to the files or in a parent directory
+	// adds project preferences on top of the user config
+	proj, err := project.ForFiles(files)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
 
 	// Get enabled slicers
-	enabledSlicers := slicer.GetEnabledSlicers()
+	enabledSlicers := proj.Apply(slicer.GetEnabledSlicers())
+
+	// Optionally order them by launch history, unless the project sets
+	// an order
+	hist, err := history.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read history: %v\n", err)
+	}
+	if proj == nil || len(proj.Order) == 0 {
+		enabledSlicers = hist.Sort(enabledSlicers, files, cfg.OrderMode)
+	}
+
+	// A remembered slicer or a matching rule opens the files without any UI
+	if !opts.Pick && launchAutomatically(cfg, enabledSlicers, files) {
+		slicer.Wait()
+		return
+	}
+
+	// File managers often start one picker per selected file; hand the
+	// files to the picker that is already open instead of opening another
+	server, forwarded := becomePrimary(files)
+	if forwarded {
+		return
+	}
+	var incoming <-chan []string
+	if server != nil {
+		incoming = server.Paths()
+	}
+
+	// Create a minimal app for the dialog
+	if fyneApp == nil {
+		fyneApp = app.NewWithID("com.qslicerpicker.selector")
+	}
 
 	if len(enabledSlicers) == 0 {
+		if server != nil {
+			server.Close()
+		}
 		fmt.Fprintf(os.Stderr, "No slicers available\n")
 		os.Exit(1)
 	}
 
-	// Show selector dialog
-	selectedSlicer := ui.ShowSlicerSelectorWithApp(fyneApp, filePath, enabledSlicers)
+	// Suggest the project's slicer, or the slicer that created the files
+	// (e.g. for 3MF projects)
+	preferred, note := projectSlicer(proj, enabledSlicers)
+	if preferred == "" {
+		preferred, note = originSlicer(files, enabledSlicers)
+	}
+	if preferred == "" {
+		preferred, note = likelySlicer(hist, enabledSlicers, files, cfg.OrderMode)
+	}
+
+	// Versions only printed by the slicers themselves are looked up while
+	// the selector is open and listed from the next start
+	if cfg.SeparateVersions {
+		go slicer.RefreshVersions()
+	}
 
-	if selectedSlicer == nil {
-		// User cancelled
+	// Show selector dialog; the slicer is launched from the dialog so that
+	// early failures can be shown to the user
+	var launchFailed atomic.Bool
+	selection := ui.ShowSlicerSelectorWithApp(fyneApp, ui.SelectorRequest{
+		Files:           files,
+		Slicers:         enabledSlicers,
+		Preferred:       preferred,
+		PreferredNote:   note,
+		HideUnsupported: cfg.HideUnsupportedSlicers,
+		Countdown:       countdown(cfg, files),
+		Incoming:        incoming,
+		Launch: func(selection *ui.Selection) error {
+			err := launch(*selection.Slicer, selection.Files)
+			launchFailed.Store(err != nil)
+			return err
+		},
+	})
+
+	// Files forwarded while the slicer was starting go to the same slicer
+	var lateFiles []string
+	if server != nil {
+		server.Close()
+		for paths := range server.Paths() {
+			lateFiles = append(lateFiles, paths...)
+		}
+	}
+
+	if selection == nil || selection.Slicer == nil {
+		// User cancelled, possibly after a failed launch whose post-exit
+		// hooks still have to run
+		slicer.Wait()
+		if launchFailed.Load() {
+			os.Exit(1)
+		}
 		os.Exit(0)
 	}
 
-	// Launch slicer with file
-	if err := slicer.LaunchSlicer(*selectedSlicer, filePath); err != nil {
+	remember(selection)
+
+	if len(lateFiles) > 0 {
+		if err := launch(*selection.Slicer, lateFiles); err != nil {
+			slicer.Wait()
+			os.Exit(1)
+		}
+	}
+
+	// Stay around for post-exit hooks
+	slicer.Wait()
+}
+
+// launchAutomatically launches the slicer remembered for the files, or the
+// one chosen by the first rule matching all files. It returns false if the
+// selector should be shown instead: nothing matched, the rule says to ask,
+// or the slicer is unavailable or failed.
+func launchAutomatically(cfg *config.Config, enabledSlicers []slicer.Slicer, files []string) bool {
+	if association, ok := rules.MatchAssociation(cfg.Associations, files); ok {
+		return launchByID(association.Slicer, enabledSlicers, files)
+	}
+
+	rule, ok := rules.Match(cfg.Rules, files)
+	if !ok || rule.Slicer == config.RuleAsk {
+		return false
+	}
+	return launchByID(rule.Slicer, enabledSlicers, files)
+}
+
+// launchByID launches the enabled slicer with the given ID
+func launchByID(id string, enabledSlicers []slicer.Slicer, files []string) bool {
+	for _, s := range enabledSlicers {
+		if s.ID == id {
+			return launch(s, files) == nil
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: slicer %q is not available\n", id)
+	return false
+}
+
+// remember stores the associations requested in the selector, replacing
+// earlier ones for the same extension or directory
+func remember(selection *ui.Selection) {
+	if selection.RememberExtension == "" && selection.RememberDirectory == "" {
+		return
+	}
+
+	cfg := config.GetConfig()
+	associations := make([]config.Association, 0, len(cfg.Associations)+2)
+	for _, association := range cfg.Associations {
+		if (selection.RememberExtension != "" && strings.EqualFold(association.Extension, selection.RememberExtension)) ||
+			(selection.RememberDirectory != "" && association.Directory == selection.RememberDirectory) {
+			continue
+		}
+		associations = append(associations, association)
+	}
+	if selection.RememberExtension != "" {
+		associations = append(associations, config.Association{
+			Extension: selection.RememberExtension,
+			Slicer:    selection.Slicer.ID,
+		})
+	}
+	if selection.RememberDirectory != "" {
+		associations = append(associations, config.Association{
+			Directory: selection.RememberDirectory,
+			Slicer:    selection.Slicer.ID,
+		})
+	}
+
+	cfg.Associations = associations
+	if err := config.SaveConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// projectSlicer returns the project's preferred slicer if it is enabled
+func projectSlicer(proj *project.Project, enabledSlicers []slicer.Slicer) (string, string) {
+	if proj == nil || proj.Slicer == "" {
+		return "", ""
+	}
+	for _, s := range enabledSlicers {
+		if s.ID == proj.Slicer {
+			return s.ID, fmt.Sprintf(i18n.T("project_preference"), filepath.Base(proj.Dir))
+		}
+	}
+	return "", ""
+}
+
+// likelySlicer returns the slicer used most often or most recently for the
+// files' type, depending on the order mode
+func likelySlicer(hist *history.History, enabledSlicers []slicer.Slicer, files []string, mode string) (string, string) {
+	id := hist.Likely(enabledSlicers, files, mode)
+	if id == "" {
+		return "", ""
+	}
+	if mode == config.OrderRecent {
+		return id, i18n.T("used_last")
+	}
+	return id, i18n.T("used_most")
+}
+
+// countdown returns how long the selector waits before opening the
+// pre-selected slicer: the setting for the files' common extension if there
+// is one, otherwise the global setting
+func countdown(cfg *config.Config, files []string) time.Duration {
+	seconds := cfg.CountdownSeconds
+	if ext := rules.CommonExtension(files); ext != "" {
+		for configured, value := range cfg.ExtensionCountdowns {
+			if strings.EqualFold(configured, ext) {
+				seconds = value
+				break
+			}
+		}
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// originSlicer returns the ID of the enabled slicer that created the files
+// together with a note naming the application, or empty strings if the files
+// have no known origin or come from different slicers
+func originSlicer(files []string, enabledSlicers []slicer.Slicer) (string, string) {
+	var origin inspect.Origin
+	for _, file := range files {
+		fileOrigin, ok := inspect.Inspect(file)
+		if !ok || fileOrigin.SlicerID == "" {
+			continue
+		}
+		if origin.SlicerID != "" && origin.SlicerID != fileOrigin.SlicerID {
+			return "", ""
+		}
+		origin = fileOrigin
+	}
+	if origin.SlicerID == "" {
+		return "", ""
+	}
+
+	for _, s := range enabledSlicers {
+		if s.ID == origin.SlicerID {
+			return s.ID, fmt.Sprintf(i18n.T("created_with"), origin.Application)
+		}
+	}
+	return "", ""
+}
+
+// needsDownload reports whether any argument is a slicer URL
+// (prusaslicer://open?file=…) or a web link
+func needsDownload(args []string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, "://") && !strings.HasPrefix(strings.ToLower(arg), "file://") {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveURLs downloads models passed as slicer URLs or web links and
+// converts file:// URLs, so the rest of the picker only sees local paths
+func resolveURLs(ctx context.Context, args []string) ([]string, error) {
+	var fetcher *fetch.Fetcher
+	resolved := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.Contains(arg, "://") {
+			resolved = append(resolved, arg)
+			continue
+		}
+		if fetcher == nil {
+			var err error
+			if fetcher, err = fetch.NewFetcher(); err != nil {
+				return nil, err
+			}
+		}
+		filePath, err := fetcher.Resolve(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, filePath)
+	}
+	return resolved, nil
+}
+
+// launch starts the slicer, reports failures on stderr and records
+// successful launches in the history
+func launch(s slicer.Slicer, files []string) error {
+	err := slicer.LaunchSlicer(s, files)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error launching slicer: %v\n", err)
-		os.Exit(1)
+		if output := slicer.ErrorOutput(err); output != "" {
+			fmt.Fprintf(os.Stderr, "%s\n", output)
+		}
+		return err
+	}
+	recordLaunch(s, files)
+	return nil
+}
+
+// recordLaunch adds a successful launch to the history
+func recordLaunch(s slicer.Slicer, files []string) {
+	hist, err := history.Load()
+	if err != nil {
+		hist = &history.History{}
+	}
+	hist.Record(s.ID, files, time.Now())
+	if err := hist.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save history: %v\n", err)
+	}
+}
+
+// becomePrimary forwards files to a running picker, or starts listening for
+// forwarded files itself. The server is nil if neither was possible, in which
+// case the picker runs standalone.
+func becomePrimary(files []string) (*instance.Server, bool) {
+	for attempt := 0; attempt < 3; attempt++ {
+		if instance.Forward(files) {
+			return nil, true
+		}
+		server, err := instance.Listen()
+		if err == nil {
+			return server, false
+		}
+		if err != instance.ErrAlreadyRunning {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			break
+		}
 	}
+	return nil, false
 }
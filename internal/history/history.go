@@ -0,0 +1,164 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"qslicerpicker/internal/config"
+	"qslicerpicker/internal/rules"
+	"qslicerpicker/internal/slicer"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileName is the history file stored next to the config file
+const FileName = "history.json"
+
+// Entry counts the launches of a slicer for one file extension. The entry
+// with an empty extension counts all launches of the slicer.
+type Entry struct {
+	Slicer    string    `json:"slicer"`
+	Extension string    `json:"extension,omitempty"`
+	Count     int       `json:"count"`
+	LastUsed  time.Time `json:"last_used"`
+}
+
+// History is the local record of slicer launches
+type History struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Path returns the location of the history file
+func Path() string {
+	return filepath.Join(filepath.Dir(config.GetConfigPath()), FileName)
+}
+
+// Load reads the history; a missing file is an empty history
+func Load() (*History, error) {
+	h := &History{}
+	data, err := os.ReadFile(Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return &History{}, err
+	}
+	return h, nil
+}
+
+// Save writes the history, replacing the file atomically so that pickers
+// running at the same time never see a partial file
+func (h *History) Save() error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(Path()), ".history-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), Path())
+}
+
+// Clear removes the history file
+func Clear() error {
+	if err := os.Remove(Path()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Record counts a launch of the slicer for each extension among filePaths
+func (h *History) Record(slicerID string, filePaths []string, now time.Time) {
+	extensions := map[string]bool{"": true}
+	for _, filePath := range filePaths {
+		extensions[extension(filePath)] = true
+	}
+	for ext := range extensions {
+		entry := h.entry(slicerID, ext)
+		if entry == nil {
+			h.Entries = append(h.Entries, Entry{Slicer: slicerID, Extension: ext})
+			entry = &h.Entries[len(h.Entries)-1]
+		}
+		entry.Count++
+		entry.LastUsed = now
+	}
+}
+
+// Sort orders the slicers by use for the files' extension, falling back to
+// overall use; slicers without history keep their order after the others.
+// mode is config.OrderRecent or config.OrderFrequent; any other mode leaves
+// the slicers unchanged.
+func (h *History) Sort(slicers []slicer.Slicer, filePaths []string, mode string) []slicer.Slicer {
+	if mode != config.OrderRecent && mode != config.OrderFrequent {
+		return slicers
+	}
+	ext := rules.CommonExtension(filePaths)
+
+	sorted := append([]slicer.Slicer(nil), slicers...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return h.less(sorted[j].ID, sorted[i].ID, ext, mode)
+	})
+	return sorted
+}
+
+// Likely returns the ID of the slicer most likely wanted for the files: the
+// first slicer after Sort, if it has been used before
+func (h *History) Likely(slicers []slicer.Slicer, filePaths []string, mode string) string {
+	if mode != config.OrderRecent && mode != config.OrderFrequent {
+		return ""
+	}
+	sorted := h.Sort(slicers, filePaths, mode)
+	if len(sorted) == 0 || h.entry(sorted[0].ID, "") == nil {
+		return ""
+	}
+	return sorted[0].ID
+}
+
+// less reports whether slicer a has been used less (or less recently) than
+// slicer b for ext, then overall
+func (h *History) less(a, b, ext string, mode string) bool {
+	for _, e := range []string{ext, ""} {
+		ea, eb := h.entry(a, e), h.entry(b, e)
+		if ea == nil && eb == nil {
+			continue
+		}
+		if ea == nil || eb == nil {
+			return ea == nil
+		}
+		if mode == config.OrderRecent {
+			if !ea.LastUsed.Equal(eb.LastUsed) {
+				return ea.LastUsed.Before(eb.LastUsed)
+			}
+		} else if ea.Count != eb.Count {
+			return ea.Count < eb.Count
+		}
+	}
+	return false
+}
+
+func (h *History) entry(slicerID, ext string) *Entry {
+	for i := range h.Entries {
+		if h.Entries[i].Slicer == slicerID && h.Entries[i].Extension == ext {
+			return &h.Entries[i]
+		}
+	}
+	return nil
+}
+
+func extension(filePath string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(filePath), "."))
+}
@@ -0,0 +1,108 @@
+package history
+
+import (
+	"qslicerpicker/internal/config"
+	"qslicerpicker/internal/slicer"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testHistory records launches on consecutive days of January 2024:
+// PrusaSlicer opened STL files on days 1-3, Cura an STL file on day 4 and a
+// 3MF project on day 5, and OrcaSlicer G-code on day 6
+func testHistory() *History {
+	day := func(n int) time.Time { return time.Date(2024, 1, n, 12, 0, 0, 0, time.UTC) }
+	h := &History{}
+	h.Record("prusaslicer", []string{"/models/a.stl"}, day(1))
+	h.Record("prusaslicer", []string{"/models/b.STL"}, day(2))
+	h.Record("prusaslicer", []string{"/models/c.stl", "/models/d.stl"}, day(3))
+	h.Record("cura", []string{"/models/e.stl"}, day(4))
+	h.Record("cura", []string{"/models/f.3mf"}, day(5))
+	h.Record("orcaslicer", []string{"/models/g.gcode"}, day(6))
+	return h
+}
+
+func TestRecord(t *testing.T) {
+	h := testHistory()
+	tests := []struct {
+		slicer string
+		ext    string
+		count  int
+		day    int
+	}{
+		{"prusaslicer", "stl", 3, 3},
+		{"prusaslicer", "", 3, 3},
+		{"cura", "stl", 1, 4},
+		{"cura", "3mf", 1, 5},
+		{"cura", "", 2, 5},
+		{"orcaslicer", "gcode", 1, 6},
+	}
+	for _, tt := range tests {
+		entry := h.entry(tt.slicer, tt.ext)
+		if entry == nil {
+			t.Errorf("no entry for %s %q", tt.slicer, tt.ext)
+			continue
+		}
+		if entry.Count != tt.count || entry.LastUsed.Day() != tt.day {
+			t.Errorf("%s %q: count %d on day %d, want %d on day %d", tt.slicer, tt.ext, entry.Count, entry.LastUsed.Day(), tt.count, tt.day)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	slicers := []slicer.Slicer{{ID: "bambustudio"}, {ID: "cura"}, {ID: "orcaslicer"}, {ID: "prusaslicer"}}
+	tests := []struct {
+		name   string
+		files  []string
+		mode   string
+		want   []string
+		likely string
+	}{
+		{"most used for STL", []string{"/x/part.stl"}, config.OrderFrequent, []string{"prusaslicer", "cura", "orcaslicer", "bambustudio"}, "prusaslicer"},
+		{"last used for STL", []string{"/x/part.stl"}, config.OrderRecent, []string{"cura", "prusaslicer", "orcaslicer", "bambustudio"}, "cura"},
+		{"3MF before overall use", []string{"/x/plate.3mf"}, config.OrderRecent, []string{"cura", "orcaslicer", "prusaslicer", "bambustudio"}, "cura"},
+		{"unused extension, most used overall", []string{"/x/part.obj"}, config.OrderFrequent, []string{"prusaslicer", "cura", "orcaslicer", "bambustudio"}, "prusaslicer"},
+		{"unused extension, last used overall", []string{"/x/part.obj"}, config.OrderRecent, []string{"orcaslicer", "cura", "prusaslicer", "bambustudio"}, "orcaslicer"},
+		{"mixed extensions", []string{"/x/part.stl", "/x/plate.3mf"}, config.OrderRecent, []string{"orcaslicer", "cura", "prusaslicer", "bambustudio"}, "orcaslicer"},
+		{"manual order", []string{"/x/part.stl"}, config.OrderManual, []string{"bambustudio", "cura", "orcaslicer", "prusaslicer"}, ""},
+	}
+	h := testHistory()
+	for _, tt := range tests {
+		var ids []string
+		for _, s := range h.Sort(slicers, tt.files, tt.mode) {
+			ids = append(ids, s.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%s: Sort() = %q, want %q", tt.name, ids, tt.want)
+		}
+		if likely := h.Likely(slicers, tt.files, tt.mode); likely != tt.likely {
+			t.Errorf("%s: Likely() = %q, want %q", tt.name, likely, tt.likely)
+		}
+	}
+	if slicers[0].ID != "bambustudio" {
+		t.Error("Sort() reordered the slicers it was given")
+	}
+}
+
+func TestSortEqualUse(t *testing.T) {
+	// Ties keep the configured order
+	h := &History{}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	h.Record("cura", []string{"/x/a.stl"}, now)
+	h.Record("prusaslicer", []string{"/x/b.stl"}, now)
+
+	slicers := []slicer.Slicer{{ID: "prusaslicer"}, {ID: "cura"}}
+	for _, mode := range []string{config.OrderRecent, config.OrderFrequent} {
+		if got := h.Sort(slicers, []string{"/x/c.stl"}, mode); got[0].ID != "prusaslicer" || got[1].ID != "cura" {
+			t.Errorf("%s: tie reordered to %s, %s", mode, got[0].ID, got[1].ID)
+		}
+	}
+}
+
+func TestLikelyWithoutHistory(t *testing.T) {
+	slicers := []slicer.Slicer{{ID: "prusaslicer"}, {ID: "cura"}}
+	if likely := (&History{}).Likely(slicers, []string{"/x/part.stl"}, config.OrderFrequent); likely != "" {
+		t.Errorf("Likely() without history = %q, want none", likely)
+	}
+}
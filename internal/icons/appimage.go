@@ -0,0 +1,110 @@
+package icons
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	appImageIconFile       = ".DirIcon"
+	appImageExtractTimeout = 10 * time.Second
+	noIconSuffix           = ".none"
+)
+
+// appImageIcon returns the .DirIcon of an AppImage. Extracting it runs the
+// AppImage with --appimage-extract, so the result is cached per AppImage
+// version, including when it has no icon.
+func appImageIcon(appImage string) (Icon, bool) {
+	info, err := os.Stat(appImage)
+	if err != nil || info.Mode()&0111 == 0 {
+		return Icon{}, false
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return Icon{}, false
+	}
+	cacheDir = filepath.Join(cacheDir, "qslicerpicker", "icons")
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%d", appImage, info.Size(), info.ModTime().UnixNano())))
+	key := hex.EncodeToString(sum[:])[:16]
+
+	for _, ext := range []string{".png", ".svg", noIconSuffix} {
+		cached := filepath.Join(cacheDir, key+ext)
+		if !fileExists(cached) {
+			continue
+		}
+		if ext == noIconSuffix {
+			return Icon{}, false
+		}
+		icon, err := FromFile(cached)
+		return icon, err == nil
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return Icon{}, false
+	}
+	data, err := extractAppImageIcon(appImage)
+	if err != nil || len(data) == 0 || len(data) > maxIconSize {
+		os.WriteFile(filepath.Join(cacheDir, key+noIconSuffix), nil, 0644)
+		return Icon{}, false
+	}
+
+	name := key + ".png"
+	if bytes.Contains(data[:min(len(data), 1024)], []byte("<svg")) {
+		name = key + ".svg"
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, name), data, 0644); err != nil {
+		return Icon{}, false
+	}
+	return Icon{Name: name, Data: data}, true
+}
+
+// extractAppImageIcon extracts .DirIcon into a temporary directory,
+// following it if it is a symbolic link to another file in the AppImage
+func extractAppImageIcon(appImage string) ([]byte, error) {
+	tmpDir, err := os.MkdirTemp("", "qslicerpicker-icon-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	name := appImageIconFile
+	for hops := 0; hops < 4; hops++ {
+		ctx, cancel := context.WithTimeout(context.Background(), appImageExtractTimeout)
+		cmd := exec.CommandContext(ctx, appImage, "--appimage-extract", name)
+		cmd.Dir = tmpDir
+		err := cmd.Run()
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		extracted := filepath.Join(tmpDir, "squashfs-root", filepath.FromSlash(name))
+		info, err := os.Lstat(extracted)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return os.ReadFile(extracted)
+		}
+
+		target, err := os.Readlink(extracted)
+		if err != nil || path.IsAbs(target) {
+			return nil, fmt.Errorf("%s links outside the AppImage", appImageIconFile)
+		}
+		name = path.Clean(path.Join(path.Dir(name), target))
+		if strings.HasPrefix(name, "..") {
+			return nil, fmt.Errorf("%s links outside the AppImage", appImageIconFile)
+		}
+	}
+	return nil, fmt.Errorf("too many links from %s", appImageIconFile)
+}
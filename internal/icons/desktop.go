@@ -0,0 +1,105 @@
+package icons
+
+import (
+	"os"
+	"path/filepath"
+	"qslicerpicker/internal/desktopentry"
+	"qslicerpicker/internal/slicer"
+	"strings"
+	"sync"
+)
+
+// themeSizes are the hicolor directories searched, best first
+var themeSizes = []string{
+	"scalable", "512x512", "256x256", "192x192", "128x128", "96x96", "64x64", "48x48", "32x32",
+}
+
+var (
+	entriesOnce sync.Once
+	entries     []*desktopentry.Entry
+)
+
+// desktopEntries returns the installed application entries, read once
+func desktopEntries() []*desktopentry.Entry {
+	entriesOnce.Do(func() {
+		entries = desktopentry.All()
+	})
+	return entries
+}
+
+// desktopIcon returns the icon file of the slicer's .desktop entry, or ""
+func desktopIcon(s slicer.Slicer) string {
+	for _, entry := range desktopEntries() {
+		if entry.Icon == "" || !launchesSlicer(entry, s) {
+			continue
+		}
+		if path := lookupIcon(entry.Icon); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// launchesSlicer reports whether the entry starts the slicer: Flatpak
+// entries are named after the app ID, others run the slicer's executable
+func launchesSlicer(entry *desktopentry.Entry, s slicer.Slicer) bool {
+	if s.FlatpakID != "" {
+		return strings.TrimSuffix(filepath.Base(entry.Path), ".desktop") == s.FlatpakID
+	}
+	command := entry.Command()
+	if command == "" || s.Path == "" {
+		return false
+	}
+	// Commands on $PATH are given without a directory
+	if !strings.ContainsRune(command, filepath.Separator) {
+		return command == filepath.Base(s.Path)
+	}
+	return slicer.SamePath(command, s.Path)
+}
+
+// lookupIcon resolves an Icon key to an image file: absolute paths are used
+// as they are, names are looked up in the hicolor theme and in pixmaps
+func lookupIcon(name string) string {
+	if filepath.IsAbs(name) {
+		if IsImage(name) && fileExists(name) {
+			return name
+		}
+		return ""
+	}
+	// Some entries name the file instead of the icon
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".png"), ".svg")
+
+	var themeDirs, pixmapDirs []string
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		themeDirs = append(themeDirs, filepath.Join(homeDir, ".icons"))
+	}
+	for _, dir := range desktopentry.DataDirs() {
+		themeDirs = append(themeDirs, filepath.Join(dir, "icons"))
+		pixmapDirs = append(pixmapDirs, filepath.Join(dir, "pixmaps"))
+	}
+
+	for _, dir := range themeDirs {
+		for _, size := range themeSizes {
+			for _, ext := range []string{".svg", ".png"} {
+				path := filepath.Join(dir, "hicolor", size, "apps", name+ext)
+				if fileExists(path) {
+					return path
+				}
+			}
+		}
+	}
+	for _, dir := range pixmapDirs {
+		for _, ext := range []string{".svg", ".png"} {
+			path := filepath.Join(dir, name+ext)
+			if fileExists(path) {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
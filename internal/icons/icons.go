@@ -0,0 +1,86 @@
+package icons
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"qslicerpicker/internal/slicer"
+	"runtime"
+	"strings"
+)
+
+// maxIconSize is the largest image file accepted as an icon
+const maxIconSize = 4 << 20
+
+//go:embed fallback/*.svg
+var fallbacks embed.FS
+
+// Icon is the image data of a slicer icon. Name keeps the file extension so
+// that SVG and bitmap images can be told apart.
+type Icon struct {
+	Name string
+	Data []byte
+}
+
+// ForSlicer finds the icon of a slicer: the image chosen in the config, the
+// icon of its .desktop file or AppImage on Linux, or the bundled icon of a
+// known slicer. It returns false if there is none.
+func ForSlicer(s slicer.Slicer) (Icon, bool) {
+	if s.Icon != "" {
+		if icon, err := FromFile(s.Icon); err == nil {
+			return icon, true
+		}
+	}
+
+	if runtime.GOOS == "linux" {
+		if path := desktopIcon(s); path != "" {
+			if icon, err := FromFile(path); err == nil {
+				return icon, true
+			}
+		}
+		if strings.HasSuffix(strings.ToLower(s.Path), ".appimage") {
+			if icon, ok := appImageIcon(s.Path); ok {
+				return icon, true
+			}
+		}
+	}
+
+	if !s.IsCustom {
+		name := s.BaseID() + ".svg"
+		if data, err := fallbacks.ReadFile("fallback/" + name); err == nil {
+			return Icon{Name: name, Data: data}, true
+		}
+	}
+	return Icon{}, false
+}
+
+// FromFile reads a PNG, JPEG or SVG image
+func FromFile(path string) (Icon, error) {
+	if !IsImage(path) {
+		return Icon{}, fmt.Errorf("%s: not a PNG, JPEG or SVG image", filepath.Base(path))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return Icon{}, err
+	}
+	if info.Size() > maxIconSize {
+		return Icon{}, errors.New("icon file is too large")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Icon{}, err
+	}
+	return Icon{Name: filepath.Base(path), Data: data}, nil
+}
+
+// IsImage reports whether the file name has an image extension that can be
+// displayed
+func IsImage(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg", ".svg":
+		return true
+	}
+	return false
+}
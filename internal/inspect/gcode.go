@@ -0,0 +1,108 @@
+package inspect
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"qslicerpicker/internal/bgcode"
+	"strings"
+)
+
+const (
+	gcodeHeaderLines = 200
+	gcodeMaxLineLen  = 64 << 10
+)
+
+// gcodeGeneratorMarkers introduce the producing application in G-code
+// header comments, e.g. "; generated by PrusaSlicer 2.7.1+win64 on …",
+// ";Generated with Cura_SteamEngine 5.6.0" or ";Sliced by ideaMaker 4.4.1"
+var gcodeGeneratorMarkers = []string{
+	"generated by",
+	"generated with",
+	"sliced by",
+}
+
+// InspectGCode reads the origin of a text G-code file from its header comments
+func InspectGCode(filePath string) (Origin, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return Origin{}, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 4096), gcodeMaxLineLen)
+	for i := 0; i < gcodeHeaderLines && scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, ";") {
+			continue
+		}
+		if application := gcodeGenerator(strings.TrimSpace(line[1:])); application != "" {
+			return newOrigin(application), nil
+		}
+	}
+	if err := scanner.Err(); err != nil && err != bufio.ErrTooLong {
+		return Origin{}, err
+	}
+	return Origin{}, nil
+}
+
+// gcodeGenerator returns the application named in a header comment
+func gcodeGenerator(comment string) string {
+	lower := strings.ToLower(comment)
+	for _, marker := range gcodeGeneratorMarkers {
+		if i := strings.Index(lower, marker); i >= 0 {
+			application := strings.TrimSpace(comment[i+len(marker):])
+			application, _, _ = strings.Cut(application, " on ")
+			return application
+		}
+	}
+
+	// Some slicers only name themselves, e.g. "; KISSlicer - FREE"
+	for _, known := range knownApplications {
+		if strings.HasPrefix(lower, known.fragment) {
+			return comment
+		}
+	}
+	return ""
+}
+
+const bgcodeMaxMetadataLen = 16 << 20
+
+// InspectBGCode reads the origin of a binary G-code file from the Producer
+// entry of its file metadata block
+func InspectBGCode(filePath string) (Origin, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return Origin{}, err
+	}
+	defer file.Close()
+
+	var origin Origin
+	err = bgcode.Walk(file, func(block bgcode.Block, data io.Reader) error {
+		if block.Type != bgcode.BlockFileMetadata && block.Type != bgcode.BlockSlicerMetadata {
+			return nil
+		}
+		metadata, err := bgcode.ReadData(block, data, bgcodeMaxMetadataLen)
+		if err != nil {
+			return err
+		}
+		if producer := bgcodeProducer(metadata); producer != "" {
+			origin = newOrigin(producer)
+			return bgcode.Stop
+		}
+		return nil
+	})
+	return origin, err
+}
+
+// bgcodeProducer returns the Producer entry of INI encoded metadata
+func bgcodeProducer(metadata []byte) string {
+	for _, line := range strings.Split(string(metadata), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "Producer") {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
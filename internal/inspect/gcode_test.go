@@ -0,0 +1,106 @@
+package inspect
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"qslicerpicker/internal/bgcode"
+	"strings"
+	"testing"
+)
+
+// writeFile writes a file with the given name and content
+func writeFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInspectGCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		application string
+		slicerID    string
+		version     string
+	}{
+		{"PrusaSlicer", "; generated by PrusaSlicer 2.7.1+linux-x64-GTK3 on 2024-01-01 at 12:00:00 UTC\n", "PrusaSlicer 2.7.1+linux-x64-GTK3", "prusaslicer", "2.7.1"},
+		{"Cura", ";FLAVOR:Marlin\n;TIME:1234\n;Generated with Cura_SteamEngine 5.6.0\nM140 S60\n", "Cura_SteamEngine 5.6.0", "cura", "5.6.0"},
+		{"OrcaSlicer", "; HEADER_BLOCK_START\n; generated by OrcaSlicer 2.0.0 on 2024-01-01 at 12:00:00\n", "OrcaSlicer 2.0.0", "orcaslicer", "2.0.0"},
+		{"ideaMaker", ";Sliced by ideaMaker 4.4.1.6532, 2024-01-01\n", "ideaMaker 4.4.1.6532, 2024-01-01", "ideamaker", "4.4.1.6532"},
+		{"KISSlicer", "; KISSlicer - FREE\n; Windows\n", "KISSlicer - FREE", "kisslicer", ""},
+		{"after commands", "G28\nG1 X10\n; generated by SuperSlicer 2.5.59\n", "SuperSlicer 2.5.59", "superslicer", "2.5.59"},
+		{"unknown", "; layer_height = 0.2\nG28\n", "", "", ""},
+	}
+	for _, tt := range tests {
+		origin, err := InspectGCode(writeFile(t, "model.gcode", []byte(tt.header+"G1 X0 Y0\n")))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if origin.Application != tt.application || origin.SlicerID != tt.slicerID || origin.Version != tt.version {
+			t.Errorf("%s: origin = %+v, want %q (%q, %q)", tt.name, origin, tt.application, tt.slicerID, tt.version)
+		}
+	}
+}
+
+func TestInspectGCodeHeaderOnly(t *testing.T) {
+	content := strings.Repeat("G1 X0 Y0\n", gcodeHeaderLines) + "; generated by PrusaSlicer 2.7.1\n"
+	origin, err := InspectGCode(writeFile(t, "model.gcode", []byte(content)))
+	if err != nil || origin.Application != "" {
+		t.Errorf("InspectGCode() = %+v, %v, want no origin past the header", origin, err)
+	}
+}
+
+// bgcodeFile encodes a binary G-code file with a deflated file metadata
+// block
+func bgcodeFile(metadata string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("GCDE")
+	binary.Write(&buf, binary.LittleEndian, uint32(1))
+	binary.Write(&buf, binary.LittleEndian, uint16(0)) // no checksums
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write([]byte(metadata))
+	zw.Close()
+	binary.Write(&buf, binary.LittleEndian, uint16(bgcode.BlockFileMetadata))
+	binary.Write(&buf, binary.LittleEndian, uint16(bgcode.CompressionDeflate))
+	binary.Write(&buf, binary.LittleEndian, uint32(len(metadata)))
+	binary.Write(&buf, binary.LittleEndian, uint32(compressed.Len()))
+	binary.Write(&buf, binary.LittleEndian, uint16(0)) // INI encoding
+	buf.Write(compressed.Bytes())
+	return buf.Bytes()
+}
+
+func TestInspectBGCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		metadata    string
+		application string
+		slicerID    string
+	}{
+		{"Producer", "Producer=PrusaSlicer 2.7.1\n", "PrusaSlicer 2.7.1", "prusaslicer"},
+		{"spaced Producer", "version = 1\n producer = PrusaSlicer 2.8.0-alpha6 \n", "PrusaSlicer 2.8.0-alpha6", "prusaslicer"},
+		{"no Producer", "Version=1\n", "", ""},
+	}
+	for _, tt := range tests {
+		origin, err := InspectBGCode(writeFile(t, "model.bgcode", bgcodeFile(tt.metadata)))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if origin.Application != tt.application || origin.SlicerID != tt.slicerID {
+			t.Errorf("%s: origin = %+v, want %q (%q)", tt.name, origin, tt.application, tt.slicerID)
+		}
+	}
+
+	if _, err := InspectBGCode(writeFile(t, "model.bgcode", []byte("; generated by PrusaSlicer 2.7.1\n"))); err != bgcode.ErrNotBGCode {
+		t.Errorf("InspectBGCode() of a text file = %v, want ErrNotBGCode", err)
+	}
+}
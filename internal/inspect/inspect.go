@@ -0,0 +1,72 @@
+package inspect
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Origin describes the application that produced a file
+type Origin struct {
+	Application string // as recorded in the file, e.g. "PrusaSlicer-2.6.1+linux-x64-GTK3"
+	SlicerID    string // ID of the matching known slicer, empty if unknown
+	Version     string
+}
+
+// knownApplications maps name fragments found in files to slicer IDs. Forks
+// come before the slicers they mention.
+var knownApplications = []struct {
+	fragment string
+	slicerID string
+}{
+	{"bambustudio", "bambustudio"},
+	{"bambu studio", "bambustudio"},
+	{"orcaslicer", "orcaslicer"},
+	{"superslicer", "superslicer"},
+	{"prusaslicer", "prusaslicer"},
+	{"slic3r prusa edition", "slic3rpe"},
+	{"slic3rpe", "slic3rpe"},
+	{"slic3r", "slic3r"},
+	{"cura", "cura"},
+	{"ideamaker", "ideamaker"},
+	{"simplify3d", "simplify3d"},
+	{"kisslicer", "kisslicer"},
+}
+
+var versionPattern = regexp.MustCompile(`\d+(?:\.\d+)+`)
+
+// Inspect identifies the application that produced a file. It returns false
+// for unsupported formats and files without a recognizable origin.
+func Inspect(filePath string) (Origin, bool) {
+	var origin Origin
+	var err error
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".3mf":
+		origin, err = Inspect3MF(filePath)
+	case ".gcode", ".gco", ".g":
+		origin, err = InspectGCode(filePath)
+	case ".bgcode":
+		origin, err = InspectBGCode(filePath)
+	default:
+		return Origin{}, false
+	}
+	if err != nil || origin.Application == "" {
+		return Origin{}, false
+	}
+	return origin, true
+}
+
+// newOrigin builds an Origin from an application string such as
+// "BambuStudio-01.08.04.51"
+func newOrigin(application string) Origin {
+	origin := Origin{Application: strings.TrimSpace(application)}
+	lower := strings.ToLower(origin.Application)
+	for _, known := range knownApplications {
+		if strings.Contains(lower, known.fragment) {
+			origin.SlicerID = known.slicerID
+			break
+		}
+	}
+	origin.Version = versionPattern.FindString(origin.Application)
+	return origin
+}
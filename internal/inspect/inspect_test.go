@@ -0,0 +1,30 @@
+package inspect
+
+import "testing"
+
+func TestNewOrigin(t *testing.T) {
+	tests := []struct {
+		application string
+		slicerID    string
+		version     string
+	}{
+		{"PrusaSlicer 2.7.1", "prusaslicer", "2.7.1"},
+		{"PrusaSlicer-2.6.1+linux-x64-GTK3", "prusaslicer", "2.6.1"},
+		{"BambuStudio-01.08.04.51", "bambustudio", "01.08.04.51"},
+		{"Bambu Studio 1.9.0", "bambustudio", "1.9.0"},
+		{"OrcaSlicer 2.0.0 based on PrusaSlicer", "orcaslicer", "2.0.0"},
+		{"SuperSlicer-2.5.59.2", "superslicer", "2.5.59.2"},
+		{"Slic3r Prusa Edition 1.41.3", "slic3rpe", "1.41.3"},
+		{"Slic3r 1.3.0", "slic3r", "1.3.0"},
+		{"Cura_SteamEngine 5.6.0", "cura", "5.6.0"},
+		{"ideaMaker 4.4.1", "ideamaker", "4.4.1"},
+		{"KISSlicer - FREE", "kisslicer", ""},
+		{"Unknown Slicer 3", "", ""},
+	}
+	for _, tt := range tests {
+		origin := newOrigin("  " + tt.application + "\n")
+		if origin.Application != tt.application || origin.SlicerID != tt.slicerID || origin.Version != tt.version {
+			t.Errorf("newOrigin(%q) = %+v, want slicer %q version %q", tt.application, origin, tt.slicerID, tt.version)
+		}
+	}
+}
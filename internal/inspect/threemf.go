@@ -0,0 +1,108 @@
+package inspect
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"qslicerpicker/internal/threemf"
+	"strings"
+)
+
+// Parts of a 3MF package that identify the producing slicer
+const (
+	slic3rConfigPart   = "Metadata/Slic3r_PE.config"
+	bambuSettingsPart  = "Metadata/project_settings.config"
+	curaDirPrefix      = "Cura/"
+	maxMetadataPartLen = 1 << 20
+)
+
+// Inspect3MF reads the origin of a 3MF file from the Application metadata of
+// its model part, falling back to the slicer-specific parts in the package
+func Inspect3MF(filePath string) (Origin, error) {
+	pkg, err := threemf.Open(filePath)
+	if err != nil {
+		return Origin{}, err
+	}
+	defer pkg.Close()
+	files := pkg.Parts
+
+	if file, ok := files[pkg.ModelPart()]; ok {
+		if application := modelApplication(file); application != "" {
+			return newOrigin(application), nil
+		}
+	}
+
+	if file, ok := files[slic3rConfigPart]; ok {
+		if generator := slic3rGenerator(file); generator != "" {
+			return newOrigin(generator), nil
+		}
+		return newOrigin("PrusaSlicer"), nil
+	}
+	if _, ok := files[bambuSettingsPart]; ok {
+		return newOrigin("BambuStudio"), nil
+	}
+	for name := range files {
+		if strings.HasPrefix(name, curaDirPrefix) {
+			return newOrigin("Cura"), nil
+		}
+	}
+
+	return Origin{}, nil
+}
+
+// modelApplication reads the Application metadata of a model part. Metadata
+// precedes the mesh data, so decoding stops at the resources element.
+func modelApplication(file *zip.File) string {
+	rc, err := file.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "resources":
+			return ""
+		case "metadata":
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "name" && strings.EqualFold(attr.Value, "Application") {
+					var value string
+					if err := decoder.DecodeElement(&value, &start); err != nil {
+						return ""
+					}
+					return value
+				}
+			}
+		}
+	}
+}
+
+// slic3rGenerator reads the "; generated by PrusaSlicer 2.6.1 on …" header of
+// a Slic3r-family config part
+func slic3rGenerator(file *zip.File) string {
+	rc, err := file.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+
+	scanner := bufio.NewScanner(io.LimitReader(rc, maxMetadataPartLen))
+	for i := 0; i < 5 && scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		if generator, ok := strings.CutPrefix(line, "; generated by "); ok {
+			generator, _, _ = strings.Cut(generator, " on ")
+			return generator
+		}
+	}
+	return ""
+}
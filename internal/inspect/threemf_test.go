@@ -0,0 +1,110 @@
+package inspect
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writePackage creates a 3MF package with the given parts
+func writePackage(t *testing.T, parts map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "project.3mf")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zw := zip.NewWriter(file)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// model returns a model part with the given metadata elements
+func model(metadata string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<model unit="millimeter" xml:lang="en-US" xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02">
+ ` + metadata + `
+ <resources><object id="1" type="model"><mesh><vertices/><triangles/></mesh></object></resources>
+ <metadata name="Application">written after the resources</metadata>
+ <build><item objectid="1"/></build>
+</model>`
+}
+
+func TestInspect3MF(t *testing.T) {
+	tests := []struct {
+		name        string
+		parts       map[string]string
+		application string
+		slicerID    string
+	}{
+		{
+			"Application metadata",
+			map[string]string{"3D/3dmodel.model": model(`<metadata name="Title">Benchy</metadata>
+ <metadata name="Application">PrusaSlicer-2.7.1+linux-x64-GTK3</metadata>`)},
+			"PrusaSlicer-2.7.1+linux-x64-GTK3", "prusaslicer",
+		},
+		{
+			"lowercase metadata name",
+			map[string]string{"3D/3dmodel.model": model(`<metadata name="application">BambuStudio-01.08.04.51</metadata>`)},
+			"BambuStudio-01.08.04.51", "bambustudio",
+		},
+		{
+			"model part from the relationships",
+			map[string]string{
+				"_rels/.rels":      `<Relationships><Relationship Target="/3D/main.model" Type="http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"/></Relationships>`,
+				"3D/main.model":    model(`<metadata name="Application">OrcaSlicer-2.0.0</metadata>`),
+				"3D/3dmodel.model": model(`<metadata name="Application">Cura 5.6.0</metadata>`),
+			},
+			"OrcaSlicer-2.0.0", "orcaslicer",
+		},
+		{
+			"Slic3r config",
+			map[string]string{
+				"3D/3dmodel.model":          model(""),
+				"Metadata/Slic3r_PE.config": "; generated by SuperSlicer 2.5.59 on 2024-01-01 at 12:00:00 UTC\n\n; layer_height = 0.2\n",
+			},
+			"SuperSlicer 2.5.59", "superslicer",
+		},
+		{
+			"Slic3r config without a header",
+			map[string]string{"3D/3dmodel.model": model(""), "Metadata/Slic3r_PE.config": "; layer_height = 0.2\n"},
+			"PrusaSlicer", "prusaslicer",
+		},
+		{
+			"Bambu project settings",
+			map[string]string{"3D/3dmodel.model": model(""), "Metadata/project_settings.config": "{}"},
+			"BambuStudio", "bambustudio",
+		},
+		{
+			"Cura parts",
+			map[string]string{"3D/3dmodel.model": model(""), "Cura/extruder.cfg": "[general]\n"},
+			"Cura", "cura",
+		},
+		{
+			"unknown",
+			map[string]string{"3D/3dmodel.model": model("")},
+			"", "",
+		},
+	}
+	for _, tt := range tests {
+		origin, err := Inspect3MF(writePackage(t, tt.parts))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if origin.Application != tt.application || origin.SlicerID != tt.slicerID {
+			t.Errorf("%s: origin = %q (%q), want %q (%q)", tt.name, origin.Application, origin.SlicerID, tt.application, tt.slicerID)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package instance
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	socketName    = "qslicerpicker.sock"
+	dialTimeout   = 500 * time.Millisecond
+	replyTimeout  = 2 * time.Second
+	maxMessageLen = 1 << 20
+)
+
+// ErrAlreadyRunning is returned by Listen when another picker owns the socket
+var ErrAlreadyRunning = errors.New("another instance is already running")
+
+type message struct {
+	Paths []string `json:"paths"`
+}
+
+// Server receives paths forwarded by later invocations of the picker
+type Server struct {
+	listener net.Listener
+	paths    chan []string
+	wg       sync.WaitGroup
+	once     sync.Once
+}
+
+// SocketPath returns the path of the Unix domain socket shared by all
+// invocations of the picker for the current user
+func SocketPath() string {
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return filepath.Join(runtimeDir, socketName)
+	}
+
+	// Without a private runtime dir, keep sockets of different users apart
+	user := os.Getenv("USERNAME")
+	if uid := os.Getuid(); uid >= 0 {
+		user = fmt.Sprintf("%d", uid)
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("qslicerpicker-%s.sock", user))
+}
+
+// Forward hands paths to an already running picker. It returns false if no
+// picker is listening, in which case the caller should become the primary.
+func Forward(paths []string) bool {
+	conn, err := net.DialTimeout("unix", SocketPath(), dialTimeout)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(replyTimeout))
+	if err := json.NewEncoder(conn).Encode(message{Paths: paths}); err != nil {
+		return false
+	}
+
+	// Wait for the primary to acknowledge that it took the paths
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	return err == nil && reply == "ok\n"
+}
+
+// Listen makes this process the primary picker. Stale sockets left behind by
+// a crashed picker are removed; a live one yields ErrAlreadyRunning.
+func Listen() (*Server, error) {
+	path := SocketPath()
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		if conn, dialErr := net.DialTimeout("unix", path, dialTimeout); dialErr == nil {
+			conn.Close()
+			return nil, ErrAlreadyRunning
+		}
+		os.Remove(path)
+		if listener, err = net.Listen("unix", path); err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+		}
+	}
+	os.Chmod(path, 0600)
+
+	server := &Server{
+		listener: listener,
+		paths:    make(chan []string, 16),
+	}
+	server.wg.Add(1)
+	go server.acceptLoop()
+	return server, nil
+}
+
+// Paths delivers the paths forwarded by other invocations. The channel is
+// closed by Close once all pending connections are handled.
+func (s *Server) Paths() <-chan []string {
+	return s.paths
+}
+
+// Close stops accepting forwarded paths and removes the socket
+func (s *Server) Close() error {
+	var err error
+	s.once.Do(func() {
+		err = s.listener.Close()
+		go func() {
+			s.wg.Wait()
+			close(s.paths)
+		}()
+	})
+	return err
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(replyTimeout))
+	var msg message
+	if err := json.NewDecoder(io.LimitReader(conn, maxMessageLen)).Decode(&msg); err != nil {
+		return
+	}
+	if len(msg.Paths) > 0 {
+		s.paths <- msg.Paths
+	}
+	conn.Write([]byte("ok\n"))
+}
@@ -0,0 +1,101 @@
+package instance
+
+import (
+	"net"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// useRuntimeDir places the socket in a temporary runtime dir
+func useRuntimeDir(t *testing.T) {
+	t.Helper()
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+}
+
+// receive waits for forwarded paths
+func receive(t *testing.T, server *Server) []string {
+	t.Helper()
+	select {
+	case paths := <-server.Paths():
+		return paths
+	case <-time.After(5 * time.Second):
+		t.Fatal("no paths received")
+		return nil
+	}
+}
+
+func TestForward(t *testing.T) {
+	useRuntimeDir(t)
+	if Forward([]string{"/models/benchy.stl"}) {
+		t.Fatal("Forward() succeeded without a listening picker")
+	}
+
+	server, err := Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(SocketPath()); err != nil {
+		t.Fatal(err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("socket mode = %v, want private to the user", info.Mode().Perm())
+	}
+	if _, err := Listen(); err != ErrAlreadyRunning {
+		t.Errorf("second Listen() = %v, want ErrAlreadyRunning", err)
+	}
+
+	first := []string{"/models/benchy.stl", "/models/my plate.3mf"}
+	second := []string{"/models/bracket.step"}
+	for _, paths := range [][]string{first, second} {
+		if !Forward(paths) {
+			t.Fatalf("Forward(%q) was not acknowledged", paths)
+		}
+		if got := receive(t, server); !reflect.DeepEqual(got, paths) {
+			t.Errorf("received %q, want %q", got, paths)
+		}
+	}
+
+	server.Close()
+	if _, ok := <-server.Paths(); ok {
+		t.Error("Paths() still open after Close")
+	}
+	if Forward(first) {
+		t.Error("Forward() succeeded after the primary closed")
+	}
+	if _, err := os.Stat(SocketPath()); !os.IsNotExist(err) {
+		t.Errorf("socket left behind after Close: %v", err)
+	}
+}
+
+func TestListenReplacesStaleSocket(t *testing.T) {
+	useRuntimeDir(t)
+
+	// A crashed picker leaves its socket file without a listener
+	listener, err := net.Listen("unix", SocketPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+	if _, err := os.Stat(SocketPath()); err != nil {
+		t.Fatalf("stale socket missing: %v", err)
+	}
+
+	if Forward([]string{"/models/benchy.stl"}) {
+		t.Fatal("Forward() succeeded on a stale socket")
+	}
+	server, err := Listen()
+	if err != nil {
+		t.Fatalf("Listen() over a stale socket = %v", err)
+	}
+	defer server.Close()
+
+	if !Forward([]string{"/models/benchy.stl"}) {
+		t.Fatal("Forward() to the new primary failed")
+	}
+	if got := receive(t, server); !reflect.DeepEqual(got, []string{"/models/benchy.stl"}) {
+		t.Errorf("received %q", got)
+	}
+}
@@ -0,0 +1,26 @@
+package mesh
+
+// Size returns the extent of the bounding box along each axis
+func (m *Mesh) Size() Vec3 {
+	min, max := m.Bounds()
+	return Vec3{max[0] - min[0], max[1] - min[1], max[2] - min[2]}
+}
+
+// Volume returns the enclosed volume as the sum of the signed volumes of
+// the tetrahedra between each triangle and the origin. It is only exact for
+// closed meshes with consistent winding, so it is an estimate for the
+// meshes found in the wild.
+func (m *Mesh) Volume() float64 {
+	var volume float64
+	for _, t := range m.Triangles {
+		a, b, c := m.Vertices[t[0]], m.Vertices[t[1]], m.Vertices[t[2]]
+		ax, ay, az := float64(a[0]), float64(a[1]), float64(a[2])
+		bx, by, bz := float64(b[0]), float64(b[1]), float64(b[2])
+		cx, cy, cz := float64(c[0]), float64(c[1]), float64(c[2])
+		volume += ax*(by*cz-bz*cy) - ay*(bx*cz-bz*cx) + az*(bx*cy-by*cx)
+	}
+	if volume < 0 {
+		volume = -volume
+	}
+	return volume / 6
+}
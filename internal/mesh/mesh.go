@@ -0,0 +1,118 @@
+package mesh
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsupported is returned for files that are not a supported mesh format
+var ErrUnsupported = errors.New("unsupported mesh format")
+
+// Vec3 is a point in model space, in the file's units (usually millimetres)
+type Vec3 [3]float32
+
+// Mesh is an indexed triangle mesh
+type Mesh struct {
+	Vertices  []Vec3
+	Triangles [][3]uint32
+
+	// BuildItems and Objects count the items of a 3MF build and the
+	// distinct objects they place; both are 0 for other formats
+	BuildItems int
+	Objects    int
+}
+
+// Load reads a mesh file, choosing the format by extension
+func Load(filePath string) (*Mesh, error) {
+	if strings.EqualFold(filepath.Ext(filePath), ".3mf") {
+		m, err := Read3MF(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(filePath), err)
+		}
+		return m, nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := bufio.NewReaderSize(file, 1<<16)
+	var m *Mesh
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".stl":
+		info, statErr := file.Stat()
+		if statErr != nil {
+			return nil, statErr
+		}
+		m, err = ReadSTL(r, info.Size())
+	case ".obj":
+		m, err = ReadOBJ(r)
+	case ".ply":
+		m, err = ReadPLY(r)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(filePath), err)
+	}
+	return m, nil
+}
+
+// Supported reports whether Load can read the file
+func Supported(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".stl", ".obj", ".ply", ".3mf":
+		return true
+	}
+	return false
+}
+
+// Bounds returns the corners of the mesh's axis-aligned bounding box
+func (m *Mesh) Bounds() (min, max Vec3) {
+	if len(m.Vertices) == 0 {
+		return Vec3{}, Vec3{}
+	}
+	min, max = m.Vertices[0], m.Vertices[0]
+	for _, v := range m.Vertices[1:] {
+		for i := 0; i < 3; i++ {
+			if v[i] < min[i] {
+				min[i] = v[i]
+			}
+			if v[i] > max[i] {
+				max[i] = v[i]
+			}
+		}
+	}
+	return min, max
+}
+
+// builder collects triangles, merging identical vertices as formats
+// like STL repeat them for every triangle
+type builder struct {
+	mesh    Mesh
+	indexes map[Vec3]uint32
+}
+
+func newBuilder() *builder {
+	return &builder{indexes: make(map[Vec3]uint32)}
+}
+
+func (b *builder) vertex(v Vec3) uint32 {
+	if index, ok := b.indexes[v]; ok {
+		return index
+	}
+	index := uint32(len(b.mesh.Vertices))
+	b.mesh.Vertices = append(b.mesh.Vertices, v)
+	b.indexes[v] = index
+	return index
+}
+
+func (b *builder) triangle(a, c, d Vec3) {
+	b.mesh.Triangles = append(b.mesh.Triangles, [3]uint32{b.vertex(a), b.vertex(c), b.vertex(d)})
+}
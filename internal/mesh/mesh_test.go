@@ -0,0 +1,299 @@
+package mesh
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The corners and outward facing triangles of a unit cube
+var (
+	cubeVertices = []Vec3{
+		{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0},
+		{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 1, 1},
+	}
+	cubeTriangles = [][3]uint32{
+		{0, 2, 1}, {0, 3, 2}, // bottom
+		{4, 5, 6}, {4, 6, 7}, // top
+		{0, 1, 5}, {0, 5, 4}, // front
+		{3, 7, 6}, {3, 6, 2}, // back
+		{0, 4, 7}, {0, 7, 3}, // left
+		{1, 2, 6}, {1, 6, 5}, // right
+	}
+)
+
+// checkMesh compares the size, triangle count and volume of a mesh
+func checkMesh(t *testing.T, name string, m *Mesh, size Vec3, triangles int, volume float64) {
+	t.Helper()
+	got := m.Size()
+	for axis := range got {
+		if math.Abs(float64(got[axis]-size[axis])) > 1e-3 {
+			t.Errorf("%s: size = %v, want %v", name, got, size)
+			break
+		}
+	}
+	if len(m.Triangles) != triangles {
+		t.Errorf("%s: %d triangles, want %d", name, len(m.Triangles), triangles)
+	}
+	if v := m.Volume(); math.Abs(v-volume) > volume*1e-4 {
+		t.Errorf("%s: volume = %g, want %g", name, v, volume)
+	}
+}
+
+// binarySTL encodes a cube with the given edge length as a binary STL
+func binarySTL(header string, edge float32) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte(fmt.Sprintf("%-80s", header))[:80])
+	binary.Write(&buf, binary.LittleEndian, uint32(len(cubeTriangles)))
+	for _, triangle := range cubeTriangles {
+		binary.Write(&buf, binary.LittleEndian, [3]float32{})
+		for _, index := range triangle {
+			v := cubeVertices[index]
+			binary.Write(&buf, binary.LittleEndian, [3]float32{v[0] * edge, v[1] * edge, v[2] * edge})
+		}
+		binary.Write(&buf, binary.LittleEndian, uint16(0))
+	}
+	return buf.Bytes()
+}
+
+// asciiSTL encodes a cube with the given edge length as an ASCII STL
+func asciiSTL(edge float32) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("solid cube\n")
+	for _, triangle := range cubeTriangles {
+		buf.WriteString("  facet normal 0 0 0\n    outer loop\n")
+		for _, index := range triangle {
+			v := cubeVertices[index]
+			fmt.Fprintf(&buf, "      vertex %g %g %g\n", v[0]*edge, v[1]*edge, v[2]*edge)
+		}
+		buf.WriteString("    endloop\n  endfacet\n")
+	}
+	buf.WriteString("endsolid cube\n")
+	return buf.Bytes()
+}
+
+func TestReadSTL(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"binary", binarySTL("binary cube", 10)},
+		{"ASCII", asciiSTL(10)},
+		{"binary starting with solid", binarySTL("solid cube exported as binary", 10)},
+	}
+	for _, tt := range tests {
+		m, err := ReadSTL(bufio.NewReader(bytes.NewReader(tt.data)), int64(len(tt.data)))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		checkMesh(t, tt.name, m, Vec3{10, 10, 10}, 12, 1000)
+		if len(m.Vertices) != 8 {
+			t.Errorf("%s: %d vertices, want 8 after merging", tt.name, len(m.Vertices))
+		}
+	}
+}
+
+func TestReadSTLInvalid(t *testing.T) {
+	data := []byte("not a mesh")
+	if _, err := ReadSTL(bufio.NewReader(bytes.NewReader(data)), int64(len(data))); err == nil {
+		t.Error("ReadSTL accepted a file that is neither binary nor ASCII STL")
+	}
+}
+
+func TestReadOBJ(t *testing.T) {
+	tests := []struct {
+		name  string
+		faces string
+	}{
+		{"plain", "f 1 4 3 2\nf 5 6 7 8\nf 1 2 6 5\nf 4 8 7 3\nf 1 5 8 4\nf 2 3 7 6\n"},
+		{"negative", "f -8 -5 -6 -7\nf -4 -3 -2 -1\nf -8 -7 -3 -4\nf -5 -1 -2 -6\nf -8 -4 -1 -5\nf -7 -6 -2 -3\n"},
+		{"with texture and normal", "f 1/1/1 4/2/1 3/3/1 2/4/1\nf 5//2 6//2 7//2 8//2\nf 1/1 2/2 6/3 5/4\n" +
+			"f 4/1/3 8/2/3 7/3/3 3/4/3\nf -8/1 -4/2 -1/3 -5/4\nf 2//6 3//6 7//6 6//6\n"},
+	}
+	var vertices strings.Builder
+	for _, v := range cubeVertices {
+		fmt.Fprintf(&vertices, "v %g %g %g\n", v[0]*2, v[1]*2, v[2]*2)
+	}
+	for _, tt := range tests {
+		obj := "# cube\no cube\n" + vertices.String() + "vt 0 0\nvn 0 0 1\ng sides\nusemtl none\n" + tt.faces
+		m, err := ReadOBJ(bufio.NewReader(strings.NewReader(obj)))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		checkMesh(t, tt.name, m, Vec3{2, 2, 2}, 12, 8)
+	}
+}
+
+func TestReadOBJIndexOutOfRange(t *testing.T) {
+	for _, obj := range []string{"v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 4\n", "v 0 0 0\nv 1 0 0\nv 0 1 0\nf -1 -2 -4\n"} {
+		if _, err := ReadOBJ(bufio.NewReader(strings.NewReader(obj))); err == nil {
+			t.Errorf("ReadOBJ(%q) accepted an index out of range", obj)
+		}
+	}
+}
+
+// plyHeader describes a cube with a normal per vertex, a list of vertex
+// indexes per face and an element that is skipped
+func plyHeader(format string) string {
+	return "ply\nformat " + format + " 1.0\ncomment made by hand\n" +
+		"element vertex 8\nproperty float x\nproperty float y\nproperty float z\nproperty uchar red\n" +
+		"element face 6\nproperty list uchar int vertex_indices\nproperty list uchar float texcoord\n" +
+		"element edge 1\nproperty int vertex1\nproperty int vertex2\nend_header\n"
+}
+
+// cubeQuads are the faces of the cube as quads
+var cubeQuads = [][4]int32{{0, 3, 2, 1}, {4, 5, 6, 7}, {0, 1, 5, 4}, {3, 7, 6, 2}, {0, 4, 7, 3}, {1, 2, 6, 5}}
+
+func asciiPLY() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(plyHeader("ascii"))
+	for _, v := range cubeVertices {
+		fmt.Fprintf(&buf, "%g %g %g 255\n", v[0]*3, v[1]*3, v[2]*3)
+	}
+	for _, quad := range cubeQuads {
+		fmt.Fprintf(&buf, "4 %d %d %d %d 2 0.5 0.5\n", quad[0], quad[1], quad[2], quad[3])
+	}
+	buf.WriteString("0 1\n")
+	return buf.Bytes()
+}
+
+func binaryPLY(order binary.ByteOrder, format string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(plyHeader(format))
+	for _, v := range cubeVertices {
+		binary.Write(&buf, order, [3]float32{v[0] * 3, v[1] * 3, v[2] * 3})
+		buf.WriteByte(255)
+	}
+	for _, quad := range cubeQuads {
+		buf.WriteByte(4)
+		binary.Write(&buf, order, quad)
+		buf.WriteByte(2)
+		binary.Write(&buf, order, [2]float32{0.5, 0.5})
+	}
+	binary.Write(&buf, order, [2]int32{0, 1})
+	return buf.Bytes()
+}
+
+func TestReadPLY(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"ASCII", asciiPLY()},
+		{"little endian", binaryPLY(binary.LittleEndian, "binary_little_endian")},
+		{"big endian", binaryPLY(binary.BigEndian, "binary_big_endian")},
+	}
+	for _, tt := range tests {
+		m, err := ReadPLY(bufio.NewReader(bytes.NewReader(tt.data)))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		checkMesh(t, tt.name, m, Vec3{3, 3, 3}, 12, 27)
+	}
+}
+
+func TestReadPLYInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"not ply\n",
+		"ply\nformat binary_middle_endian 1.0\nend_header\n",
+		"ply\nformat ascii 1.0\nproperty float x\nend_header\n",
+		"ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nelement face 1\nproperty list uchar int vertex_indices\nend_header\n0\n3 0 1 2\n",
+	}
+	for _, data := range tests {
+		if _, err := ReadPLY(bufio.NewReader(strings.NewReader(data))); err == nil {
+			t.Errorf("ReadPLY(%q) succeeded", data)
+		}
+	}
+}
+
+// cubeObject returns a 3MF object with a unit cube mesh
+func cubeObject(id int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<object id="%d" type="model"><mesh><vertices>`, id)
+	for _, v := range cubeVertices {
+		fmt.Fprintf(&b, `<vertex x="%g" y="%g" z="%g"/>`, v[0], v[1], v[2])
+	}
+	b.WriteString("</vertices><triangles>")
+	for _, triangle := range cubeTriangles {
+		fmt.Fprintf(&b, `<triangle v1="%d" v2="%d" v3="%d"/>`, triangle[0], triangle[1], triangle[2])
+	}
+	b.WriteString("</triangles></mesh></object>")
+	return b.String()
+}
+
+// write3MF creates a 3MF package with the given root model
+func write3MF(t *testing.T, model string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "model.3mf")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zw := zip.NewWriter(file)
+	w, err := zw.Create("3D/3dmodel.model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(model))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRead3MF(t *testing.T) {
+	// Object 3 places object 2, which places two cubes side by side; the
+	// build adds another cube on top
+	model := `<?xml version="1.0" encoding="UTF-8"?>
+<model unit="inch" xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02">
+  <resources>
+    ` + cubeObject(1) + `
+    <object id="2" type="model"><components>
+      <component objectid="1"/>
+      <component objectid="1" transform="1 0 0 0 1 0 0 0 1 2 0 0"/>
+    </components></object>
+    <object id="3" type="model"><components>
+      <component objectid="2" transform="1 0 0 0 1 0 0 0 1 0 0 1"/>
+    </components></object>
+  </resources>
+  <build>
+    <item objectid="3"/>
+    <item objectid="1" transform="1 0 0 0 1 0 0 0 1 0 0 5"/>
+  </build>
+</model>`
+	m, err := Read3MF(write3MF(t, model))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const inch = 25.4
+	checkMesh(t, "3MF", m, Vec3{3 * inch, 1 * inch, 5 * inch}, 36, 3*inch*inch*inch)
+	if m.BuildItems != 2 || m.Objects != 2 {
+		t.Errorf("BuildItems, Objects = %d, %d, want 2, 2", m.BuildItems, m.Objects)
+	}
+}
+
+func TestRead3MFInvalid(t *testing.T) {
+	tests := map[string]string{
+		"empty build":       `<model><resources>` + cubeObject(1) + `</resources><build/></model>`,
+		"missing object":    `<model><resources>` + cubeObject(1) + `</resources><build><item objectid="2"/></build></model>`,
+		"component cycle":   `<model><resources><object id="1"><components><component objectid="1"/></components></object></resources><build><item objectid="1"/></build></model>`,
+		"invalid transform": `<model><resources>` + cubeObject(1) + `</resources><build><item objectid="1" transform="1 0 0"/></build></model>`,
+	}
+	for name, model := range tests {
+		if _, err := Read3MF(write3MF(t, model)); err == nil {
+			t.Errorf("%s: Read3MF succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package mesh
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ReadOBJ reads the vertices and faces of a Wavefront OBJ file; polygons are
+// split into triangles, and normals, texture coordinates and materials are
+// ignored
+func ReadOBJ(r *bufio.Reader) (*Mesh, error) {
+	m := &Mesh{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "v":
+			v, err := parseVec3(fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			m.Vertices = append(m.Vertices, v)
+		case "f":
+			indexes := make([]uint32, 0, len(fields)-1)
+			for _, field := range fields[1:] {
+				index, err := objIndex(field, len(m.Vertices))
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				indexes = append(indexes, index)
+			}
+			for i := 2; i < len(indexes); i++ {
+				m.Triangles = append(m.Triangles, [3]uint32{indexes[0], indexes[i-1], indexes[i]})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(m.Triangles) == 0 {
+		return nil, errors.New("no faces")
+	}
+	return m, nil
+}
+
+// objIndex converts a face vertex reference such as "3", "3/1/2" or "-1"
+// to a zero-based vertex index
+func objIndex(field string, vertexCount int) (uint32, error) {
+	field, _, _ = strings.Cut(field, "/")
+	index, err := strconv.Atoi(field)
+	if err != nil {
+		return 0, err
+	}
+	// Negative indexes count back from the last vertex read
+	if index < 0 {
+		index += vertexCount + 1
+	}
+	if index < 1 || index > vertexCount {
+		return 0, fmt.Errorf("vertex index %s out of range", field)
+	}
+	return uint32(index - 1), nil
+}
@@ -0,0 +1,255 @@
+package mesh
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var errInvalidPLY = errors.New("invalid PLY file")
+
+// plyProperty is a scalar or list property of a PLY element
+type plyProperty struct {
+	name      string
+	valueType string
+	countType string // set for list properties
+}
+
+type plyElement struct {
+	name       string
+	count      int
+	properties []plyProperty
+}
+
+// ReadPLY reads the vertex positions and faces of an ASCII or binary PLY
+// file; other elements and properties are skipped
+func ReadPLY(r *bufio.Reader) (*Mesh, error) {
+	format, elements, err := readPLYHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var values plyValueReader
+	switch format {
+	case "ascii":
+		values = &plyASCIIReader{r: r}
+	case "binary_little_endian":
+		values = &plyBinaryReader{r: r, order: binary.LittleEndian}
+	case "binary_big_endian":
+		values = &plyBinaryReader{r: r, order: binary.BigEndian}
+	default:
+		return nil, fmt.Errorf("unsupported PLY format %q", format)
+	}
+
+	m := &Mesh{}
+	for _, element := range elements {
+		for i := 0; i < element.count; i++ {
+			var v Vec3
+			var indexes []uint32
+			for _, property := range element.properties {
+				if property.countType != "" {
+					n, err := values.read(property.countType)
+					if err != nil {
+						return nil, err
+					}
+					if n < 0 || n > math.MaxUint16 {
+						return nil, errInvalidPLY
+					}
+					list := make([]uint32, int(n))
+					for j := range list {
+						value, err := values.read(property.valueType)
+						if err != nil {
+							return nil, err
+						}
+						list[j] = uint32(value)
+					}
+					if property.name == "vertex_indices" || property.name == "vertex_index" {
+						indexes = list
+					}
+					continue
+				}
+
+				value, err := values.read(property.valueType)
+				if err != nil {
+					return nil, err
+				}
+				switch property.name {
+				case "x":
+					v[0] = float32(value)
+				case "y":
+					v[1] = float32(value)
+				case "z":
+					v[2] = float32(value)
+				}
+			}
+			if err := values.endElement(); err != nil {
+				return nil, err
+			}
+
+			switch element.name {
+			case "vertex":
+				m.Vertices = append(m.Vertices, v)
+			case "face":
+				for j := 2; j < len(indexes); j++ {
+					m.Triangles = append(m.Triangles, [3]uint32{indexes[0], indexes[j-1], indexes[j]})
+				}
+			}
+		}
+	}
+
+	for _, triangle := range m.Triangles {
+		for _, index := range triangle {
+			if int(index) >= len(m.Vertices) {
+				return nil, fmt.Errorf("vertex index %d out of range", index)
+			}
+		}
+	}
+	if len(m.Triangles) == 0 {
+		return nil, errors.New("no faces")
+	}
+	return m, nil
+}
+
+// readPLYHeader reads the header up to end_header
+func readPLYHeader(r *bufio.Reader) (string, []plyElement, error) {
+	var format string
+	var elements []plyElement
+	for line := 0; ; line++ {
+		text, err := r.ReadString('\n')
+		if err != nil {
+			return "", nil, errInvalidPLY
+		}
+		fields := strings.Fields(text)
+		if line == 0 {
+			if len(fields) != 1 || fields[0] != "ply" {
+				return "", nil, errInvalidPLY
+			}
+			continue
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 {
+				return "", nil, errInvalidPLY
+			}
+			format = fields[1]
+		case "element":
+			if len(fields) < 3 {
+				return "", nil, errInvalidPLY
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return "", nil, errInvalidPLY
+			}
+			elements = append(elements, plyElement{name: fields[1], count: count})
+		case "property":
+			if len(elements) == 0 {
+				return "", nil, errInvalidPLY
+			}
+			element := &elements[len(elements)-1]
+			if len(fields) == 5 && fields[1] == "list" {
+				element.properties = append(element.properties, plyProperty{name: fields[4], valueType: fields[3], countType: fields[2]})
+			} else if len(fields) == 3 {
+				element.properties = append(element.properties, plyProperty{name: fields[2], valueType: fields[1]})
+			} else {
+				return "", nil, errInvalidPLY
+			}
+		case "end_header":
+			return format, elements, nil
+		}
+	}
+}
+
+// plyValueReader reads the values of an element in the body of a PLY file
+type plyValueReader interface {
+	read(valueType string) (float64, error)
+	endElement() error
+}
+
+// plyASCIIReader reads one element per line
+type plyASCIIReader struct {
+	r      *bufio.Reader
+	fields []string
+}
+
+func (a *plyASCIIReader) read(string) (float64, error) {
+	for len(a.fields) == 0 {
+		line, err := a.r.ReadString('\n')
+		if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+			return 0, err
+		}
+		a.fields = strings.Fields(line)
+	}
+	value, err := strconv.ParseFloat(a.fields[0], 64)
+	a.fields = a.fields[1:]
+	return value, err
+}
+
+func (a *plyASCIIReader) endElement() error {
+	a.fields = nil
+	return nil
+}
+
+type plyBinaryReader struct {
+	r     io.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+func (b *plyBinaryReader) read(valueType string) (float64, error) {
+	size := plyTypeSize(valueType)
+	if size == 0 {
+		return 0, fmt.Errorf("unknown PLY type %q", valueType)
+	}
+	data := b.buf[:size]
+	if _, err := io.ReadFull(b.r, data); err != nil {
+		return 0, err
+	}
+
+	switch valueType {
+	case "char", "int8":
+		return float64(int8(data[0])), nil
+	case "uchar", "uint8":
+		return float64(data[0]), nil
+	case "short", "int16":
+		return float64(int16(b.order.Uint16(data))), nil
+	case "ushort", "uint16":
+		return float64(b.order.Uint16(data)), nil
+	case "int", "int32":
+		return float64(int32(b.order.Uint32(data))), nil
+	case "uint", "uint32":
+		return float64(b.order.Uint32(data)), nil
+	case "float", "float32":
+		return float64(math.Float32frombits(b.order.Uint32(data))), nil
+	default: // double, float64
+		return math.Float64frombits(b.order.Uint64(data)), nil
+	}
+}
+
+func (b *plyBinaryReader) endElement() error {
+	return nil
+}
+
+// plyTypeSize returns the size in bytes of a PLY scalar type, or 0 if it is
+// unknown
+func plyTypeSize(valueType string) int {
+	switch valueType {
+	case "char", "uchar", "int8", "uint8":
+		return 1
+	case "short", "ushort", "int16", "uint16":
+		return 2
+	case "int", "uint", "int32", "uint32", "float", "float32":
+		return 4
+	case "double", "float64":
+		return 8
+	}
+	return 0
+}
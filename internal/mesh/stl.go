@@ -0,0 +1,113 @@
+package mesh
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	stlHeaderLen   = 80
+	stlTriangleLen = 50
+)
+
+var errInvalidSTL = errors.New("invalid STL file")
+
+// ReadSTL reads a binary or ASCII STL file of the given size. Binary files
+// may also start with "solid", so the size decides when it matches the
+// triangle count.
+func ReadSTL(r *bufio.Reader, size int64) (*Mesh, error) {
+	header, err := r.Peek(stlHeaderLen + 4)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(header) == stlHeaderLen+4 {
+		count := int64(binary.LittleEndian.Uint32(header[stlHeaderLen:]))
+		if size == stlHeaderLen+4+count*stlTriangleLen {
+			return readBinarySTL(r, count)
+		}
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(header), []byte("solid")) {
+		return readASCIISTL(r)
+	}
+	return nil, errInvalidSTL
+}
+
+func readBinarySTL(r io.Reader, count int64) (*Mesh, error) {
+	if _, err := io.CopyN(io.Discard, r, stlHeaderLen+4); err != nil {
+		return nil, err
+	}
+
+	b := newBuilder()
+	b.mesh.Triangles = make([][3]uint32, 0, count)
+	record := make([]byte, stlTriangleLen)
+	for i := int64(0); i < count; i++ {
+		if _, err := io.ReadFull(r, record); err != nil {
+			return nil, err
+		}
+		// The normal (first 12 bytes) is recomputed when needed
+		var corners [3]Vec3
+		for c := range corners {
+			for axis := 0; axis < 3; axis++ {
+				offset := 12 + c*12 + axis*4
+				corners[c][axis] = math.Float32frombits(binary.LittleEndian.Uint32(record[offset:]))
+			}
+		}
+		b.triangle(corners[0], corners[1], corners[2])
+	}
+	return &b.mesh, nil
+}
+
+func readASCIISTL(r *bufio.Reader) (*Mesh, error) {
+	b := newBuilder()
+	var corners []Vec3
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToLower(fields[0]) {
+		case "vertex":
+			v, err := parseVec3(fields[1:])
+			if err != nil {
+				return nil, err
+			}
+			corners = append(corners, v)
+		case "endloop":
+			// Facets are triangles, but polygons are fanned just in case
+			for i := 2; i < len(corners); i++ {
+				b.triangle(corners[0], corners[i-1], corners[i])
+			}
+			corners = corners[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(b.mesh.Triangles) == 0 {
+		return nil, errInvalidSTL
+	}
+	return &b.mesh, nil
+}
+
+// parseVec3 parses the first three fields as coordinates
+func parseVec3(fields []string) (Vec3, error) {
+	var v Vec3
+	if len(fields) < 3 {
+		return v, errors.New("vertex needs three coordinates")
+	}
+	for axis := 0; axis < 3; axis++ {
+		value, err := strconv.ParseFloat(fields[axis], 32)
+		if err != nil {
+			return v, err
+		}
+		v[axis] = float32(value)
+	}
+	return v, nil
+}
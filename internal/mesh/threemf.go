@@ -0,0 +1,276 @@
+package mesh
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"qslicerpicker/internal/threemf"
+	"strconv"
+	"strings"
+)
+
+const threeMFMaxDepth = 16
+
+// threeMFUnits converts the units of a 3MF model to millimetres
+var threeMFUnits = map[string]float32{
+	"micron":     0.001,
+	"millimeter": 1,
+	"centimeter": 10,
+	"inch":       25.4,
+	"foot":       304.8,
+	"meter":      1000,
+}
+
+// matrix is an affine 3MF transform: three rows of the linear part followed
+// by the translation, applied to row vectors
+type matrix [12]float64
+
+var identity = matrix{1, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0}
+
+// apply transforms a point
+func (m matrix) apply(v Vec3) Vec3 {
+	x, y, z := float64(v[0]), float64(v[1]), float64(v[2])
+	return Vec3{
+		float32(x*m[0] + y*m[3] + z*m[6] + m[9]),
+		float32(x*m[1] + y*m[4] + z*m[7] + m[10]),
+		float32(x*m[2] + y*m[5] + z*m[8] + m[11]),
+	}
+}
+
+// then returns the transform applying m first and n second
+func (m matrix) then(n matrix) matrix {
+	var r matrix
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 3; col++ {
+			for k := 0; k < 3; k++ {
+				r[row*3+col] += m[row*3+k] * n[k*3+col]
+			}
+			if row == 3 {
+				r[row*3+col] += n[9+col]
+			}
+		}
+	}
+	return r
+}
+
+func parseMatrix(text string) (matrix, error) {
+	if strings.TrimSpace(text) == "" {
+		return identity, nil
+	}
+	fields := strings.Fields(text)
+	if len(fields) != 12 {
+		return identity, fmt.Errorf("invalid transform %q", text)
+	}
+	var m matrix
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return identity, err
+		}
+		m[i] = value
+	}
+	return m, nil
+}
+
+// threeMFReference places an object, possibly from another model part
+// (production extension)
+type threeMFReference struct {
+	objectID  string
+	part      string
+	transform matrix
+}
+
+type threeMFObject struct {
+	vertices   []Vec3
+	triangles  [][3]uint32
+	components []threeMFReference
+}
+
+type threeMFModel struct {
+	unit    float32
+	objects map[string]*threeMFObject
+	build   []threeMFReference
+}
+
+// Read3MF reads the objects placed by the build of a 3MF package as one
+// mesh in millimetres, with their transforms applied
+func Read3MF(filePath string) (*Mesh, error) {
+	pkg, err := threemf.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+	r := &threeMFReader{parts: pkg.Parts, models: make(map[string]*threeMFModel)}
+
+	rootPart := pkg.ModelPart()
+	root, err := r.model(rootPart)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Mesh{BuildItems: len(root.build)}
+	objects := make(map[string]bool)
+	for _, item := range root.build {
+		part := rootPart
+		if item.part != "" {
+			part = item.part
+		}
+		objects[part+"#"+item.objectID] = true
+		if err := r.add(m, part, item.objectID, item.transform, 0); err != nil {
+			return nil, err
+		}
+	}
+	m.Objects = len(objects)
+
+	if unit := root.unit; unit != 1 {
+		for i, v := range m.Vertices {
+			m.Vertices[i] = Vec3{v[0] * unit, v[1] * unit, v[2] * unit}
+		}
+	}
+	if len(m.Triangles) == 0 {
+		return nil, errors.New("no objects in the build")
+	}
+	return m, nil
+}
+
+type threeMFReader struct {
+	parts  map[string]*zip.File
+	models map[string]*threeMFModel
+}
+
+// add appends an object with its components to the mesh
+func (r *threeMFReader) add(m *Mesh, part, objectID string, transform matrix, depth int) error {
+	if depth > threeMFMaxDepth {
+		return errors.New("components nested too deeply")
+	}
+	model, err := r.model(part)
+	if err != nil {
+		return err
+	}
+	object, ok := model.objects[objectID]
+	if !ok {
+		return fmt.Errorf("object %s not found in %s", objectID, part)
+	}
+
+	offset := uint32(len(m.Vertices))
+	for _, v := range object.vertices {
+		m.Vertices = append(m.Vertices, transform.apply(v))
+	}
+	for _, t := range object.triangles {
+		m.Triangles = append(m.Triangles, [3]uint32{t[0] + offset, t[1] + offset, t[2] + offset})
+	}
+
+	for _, component := range object.components {
+		componentPart := part
+		if component.part != "" {
+			componentPart = component.part
+		}
+		if err := r.add(m, componentPart, component.objectID, component.transform.then(transform), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// model parses a model part once
+func (r *threeMFReader) model(part string) (*threeMFModel, error) {
+	if model, ok := r.models[part]; ok {
+		return model, nil
+	}
+	file, ok := r.parts[part]
+	if !ok {
+		return nil, fmt.Errorf("missing model part %s", part)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	model, err := parseModel(rc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", part, err)
+	}
+	r.models[part] = model
+	return model, nil
+}
+
+// parseModel reads the objects and build items of a model part, streaming
+// as meshes can have millions of vertices
+func parseModel(rc io.Reader) (*threeMFModel, error) {
+	model := &threeMFModel{unit: 1, objects: make(map[string]*threeMFObject)}
+	decoder := xml.NewDecoder(rc)
+	var object *threeMFObject
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return model, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			attrs := make(map[string]string, len(element.Attr))
+			for _, attr := range element.Attr {
+				attrs[attr.Name.Local] = attr.Value
+			}
+
+			switch element.Name.Local {
+			case "model":
+				if unit, ok := threeMFUnits[attrs["unit"]]; ok {
+					model.unit = unit
+				}
+			case "object":
+				object = &threeMFObject{}
+				model.objects[attrs["id"]] = object
+			case "vertex":
+				if object == nil {
+					continue
+				}
+				v, err := parseVec3([]string{attrs["x"], attrs["y"], attrs["z"]})
+				if err != nil {
+					return nil, err
+				}
+				object.vertices = append(object.vertices, v)
+			case "triangle":
+				if object == nil {
+					continue
+				}
+				var t [3]uint32
+				for i, name := range []string{"v1", "v2", "v3"} {
+					index, err := strconv.ParseUint(attrs[name], 10, 32)
+					if err != nil {
+						return nil, err
+					}
+					if int(index) >= len(object.vertices) {
+						return nil, fmt.Errorf("vertex index %d out of range", index)
+					}
+					t[i] = uint32(index)
+				}
+				object.triangles = append(object.triangles, t)
+			case "component", "item":
+				transform, err := parseMatrix(attrs["transform"])
+				if err != nil {
+					return nil, err
+				}
+				reference := threeMFReference{objectID: attrs["objectid"], transform: transform}
+				if attrs["path"] != "" {
+					reference.part = threemf.PartName(attrs["path"])
+				}
+				if element.Name.Local == "item" {
+					model.build = append(model.build, reference)
+				} else if object != nil {
+					object.components = append(object.components, reference)
+				}
+			}
+		case xml.EndElement:
+			if element.Name.Local == "object" {
+				object = nil
+			}
+		}
+	}
+}
@@ -12,7 +12,7 @@ import (
 
 // RegisterFileAssociations registers file associations on macOS
 func RegisterFileAssociations() error {
-	extensions := []string{"3mf", "step", "stl", "svg", "obj", "amf", "usd", "usda", "usdc", "abc", "ply", "sla"}
+	extensions := []string{"3mf", "step", "stl", "svg", "obj", "amf", "usd", "usda", "usdc", "abc", "ply", "sla", "gcode", "bgcode"}
 
 	appPath, err := getAppPath()
 	if err != nil {
@@ -29,6 +29,9 @@ func RegisterFileAssociations() error {
 		}
 	}
 
+	// Slicer URL schemes are not registered: macOS delivers links as Apple
+	// Events rather than arguments, which the picker does not handle
+
 	return nil
 }
 
@@ -8,12 +8,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"qslicerpicker/internal/fetch"
 	"strings"
 )
 
 // RegisterFileAssociations registers file associations on Linux
 func RegisterFileAssociations() error {
-	extensions := []string{"3mf", "step", "stl", "svg", "obj", "amf", "usd", "usda", "usdc", "abc", "ply", "sla"}
+	extensions := []string{"3mf", "step", "stl", "svg", "obj", "amf", "usd", "usda", "usdc", "abc", "ply", "sla", "gcode", "bgcode"}
+	schemes := fetch.Schemes
 
 	appPath, err := getAppPath()
 	if err != nil {
@@ -34,10 +36,10 @@ func RegisterFileAssociations() error {
 	desktopFile := filepath.Join(desktopDir, "qslicerpicker.desktop")
 	desktopContent := fmt.Sprintf(`[Desktop Entry]
 Name=3D Slicer Picker
-Exec=%s %%f
+Exec=%s %%U
 Type=Application
 MimeType=%s
-`, appPath, strings.Join(getMimeTypes(extensions), ";"))
+`, appPath, strings.Join(append(getMimeTypes(extensions), getSchemeHandlers(schemes)...), ";"))
 
 	if err := os.WriteFile(desktopFile, []byte(desktopContent), 0644); err != nil {
 		return fmt.Errorf("failed to write desktop file: %w", err)
@@ -54,9 +56,23 @@ MimeType=%s
 		cmd.Run() // Ignore errors
 	}
 
+	// Handle "Open in slicer" links from model websites
+	for _, handler := range getSchemeHandlers(schemes) {
+		cmd := exec.Command("xdg-mime", "default", "qslicerpicker.desktop", handler)
+		cmd.Run() // Ignore errors
+	}
+
 	return nil
 }
 
+func getSchemeHandlers(schemes []string) []string {
+	handlers := make([]string, 0, len(schemes))
+	for _, scheme := range schemes {
+		handlers = append(handlers, "x-scheme-handler/"+scheme)
+	}
+	return handlers
+}
+
 func getMimeTypes(extensions []string) []string {
 	mimeTypes := make([]string, 0, len(extensions))
 	for _, ext := range extensions {
@@ -67,18 +83,20 @@ func getMimeTypes(extensions []string) []string {
 
 func getMimeType(ext string) string {
 	mimeMap := map[string]string{
-		"3mf":  "model/3mf",
-		"step": "application/step",
-		"stl":  "model/stl",
-		"svg":  "image/svg+xml",
-		"obj":  "model/obj",
-		"amf":  "application/x-amf",
-		"usd":  "model/vnd.usd",
-		"usda": "model/vnd.usd",
-		"usdc": "model/vnd.usd",
-		"abc":  "application/x-abc",
-		"ply":  "model/ply",
-		"sla":  "application/x-sla",
+		"3mf":    "model/3mf",
+		"step":   "application/step",
+		"stl":    "model/stl",
+		"svg":    "image/svg+xml",
+		"obj":    "model/obj",
+		"amf":    "application/x-amf",
+		"usd":    "model/vnd.usd",
+		"usda":   "model/vnd.usd",
+		"usdc":   "model/vnd.usd",
+		"abc":    "application/x-abc",
+		"ply":    "model/ply",
+		"sla":    "application/x-sla",
+		"gcode":  "text/x.gcode",
+		"bgcode": "application/x-bgcode",
 	}
 
 	if mime, ok := mimeMap[ext]; ok {
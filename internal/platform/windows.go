@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"qslicerpicker/internal/fetch"
 	"syscall"
 
 	"golang.org/x/sys/windows/registry"
@@ -19,7 +20,8 @@ var (
 
 // RegisterFileAssociations registers file associations on Windows
 func RegisterFileAssociations() error {
-	extensions := []string{"3mf", "step", "stl", "svg", "obj", "amf", "usd", "usda", "usdc", "abc", "ply", "sla"}
+	extensions := []string{"3mf", "step", "stl", "svg", "obj", "amf", "usd", "usda", "usdc", "abc", "ply", "sla", "gcode", "bgcode"}
+	schemes := fetch.Schemes
 
 	appPath, err := getAppPath()
 	if err != nil {
@@ -61,6 +63,28 @@ func RegisterFileAssociations() error {
 		commandKey.SetStringValue("", fmt.Sprintf(`"%s" "%%1"`, appPath))
 	}
 
+	// Handle "Open in slicer" links from model websites
+	for _, scheme := range schemes {
+		schemePath := fmt.Sprintf(`Software\Classes\%s`, scheme)
+		schemeKey, _, err := registry.CreateKey(registry.CURRENT_USER, schemePath, registry.ALL_ACCESS)
+		if err != nil {
+			continue
+		}
+
+		schemeKey.SetStringValue("", fmt.Sprintf("URL:%s Protocol", scheme))
+		schemeKey.SetStringValue("URL Protocol", "")
+		schemeKey.Close()
+
+		commandPath := fmt.Sprintf(`Software\Classes\%s\shell\open\command`, scheme)
+		commandKey, _, err := registry.CreateKey(registry.CURRENT_USER, commandPath, registry.ALL_ACCESS)
+		if err != nil {
+			continue
+		}
+
+		commandKey.SetStringValue("", fmt.Sprintf(`"%s" "%%1"`, appPath))
+		commandKey.Close()
+	}
+
 	// Notify shell of changes
 	procSHChangeNotify.Call(
 		uintptr(0x8000000), // SHCNE_ASSOCCHANGED
@@ -0,0 +1,105 @@
+package preview
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"image"
+	"io"
+	"os"
+	"qslicerpicker/internal/bgcode"
+	"strings"
+)
+
+// ThumbnailGCode returns the largest thumbnail embedded in the header of a
+// text G-code file, between "; thumbnail begin WxH length" and
+// "; thumbnail end" comments (also the thumbnail_PNG and thumbnail_JPG
+// variants). Scanning stops at the first G-code command.
+func ThumbnailGCode(filePath string) (image.Image, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var images []image.Image
+	var encoded *strings.Builder
+	scanner := bufio.NewScanner(io.LimitReader(file, maxThumbnailLen))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, ";") {
+			break
+		}
+		comment := strings.TrimSpace(line[1:])
+
+		fields := strings.Fields(comment)
+		switch {
+		case len(fields) >= 2 && strings.HasPrefix(fields[0], "thumbnail") && fields[1] == "begin":
+			encoded = &strings.Builder{}
+		case len(fields) >= 2 && strings.HasPrefix(fields[0], "thumbnail") && fields[1] == "end":
+			if encoded == nil {
+				continue
+			}
+			if img, err := decodeBase64Image(encoded.String()); err == nil {
+				images = append(images, img)
+			}
+			encoded = nil
+		case encoded != nil:
+			encoded.WriteString(comment)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if img := largest(images); img != nil {
+		return img, nil
+	}
+	return nil, ErrNoPreview
+}
+
+func decodeBase64Image(encoded string) (image.Image, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// ThumbnailBGCode returns the largest PNG or JPEG thumbnail block of a
+// binary G-code file
+func ThumbnailBGCode(filePath string) (image.Image, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var images []image.Image
+	err = bgcode.Walk(file, func(block bgcode.Block, data io.Reader) error {
+		if block.Type != bgcode.BlockThumbnail {
+			return nil
+		}
+		// QOI thumbnails cannot be decoded and are skipped by image.Decode
+		encoded, err := bgcode.ReadData(block, data, maxThumbnailLen)
+		if err != nil {
+			return nil
+		}
+		if img, _, err := image.Decode(bytes.NewReader(encoded)); err == nil {
+			images = append(images, img)
+		}
+		return nil
+	})
+	if err == bgcode.ErrNotBGCode {
+		return nil, ErrNoPreview
+	}
+
+	if img := largest(images); img != nil {
+		return img, nil
+	}
+	return nil, ErrNoPreview
+}
@@ -0,0 +1,90 @@
+package preview
+
+import (
+	"errors"
+	"image"
+	_ "image/jpeg" // thumbnails may be JPEG
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"qslicerpicker/internal/mesh"
+	"strings"
+)
+
+// MaxMeshFileSize is the largest mesh file loaded for a preview; larger
+// files take too long to load for the selector
+const MaxMeshFileSize = 256 << 20
+
+// ErrNoPreview is returned for files without an embedded thumbnail that
+// cannot be rendered either
+var ErrNoPreview = errors.New("no preview available")
+
+// Load returns a preview of the file and its mesh, either of which may be
+// nil if the file has none: the embedded thumbnail if there is one,
+// otherwise a size×size rendering of the mesh
+func Load(filePath string, size int) (image.Image, *mesh.Mesh, error) {
+	thumbnail, err := Thumbnail(filePath)
+	if err != nil && !errors.Is(err, ErrNoPreview) {
+		return nil, nil, err
+	}
+
+	m, meshErr := loadMesh(filePath)
+	if thumbnail != nil {
+		return thumbnail, m, nil
+	}
+	if meshErr != nil {
+		return nil, nil, meshErr
+	}
+	return Render(m, size), m, nil
+}
+
+// loadMesh loads the mesh of the file unless it is too large
+func loadMesh(filePath string) (*mesh.Mesh, error) {
+	if !mesh.Supported(filePath) {
+		return nil, ErrNoPreview
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > MaxMeshFileSize {
+		return nil, ErrNoPreview
+	}
+	return mesh.Load(filePath)
+}
+
+// Supported reports whether a preview can be attempted for the file
+func Supported(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".gcode", ".gco", ".g", ".bgcode":
+		return true
+	}
+	return mesh.Supported(filePath)
+}
+
+// Thumbnail returns the thumbnail embedded in a 3MF, G-code or binary G-code
+// file, or ErrNoPreview
+func Thumbnail(filePath string) (image.Image, error) {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".3mf":
+		return Thumbnail3MF(filePath)
+	case ".gcode", ".gco", ".g":
+		return ThumbnailGCode(filePath)
+	case ".bgcode":
+		return ThumbnailBGCode(filePath)
+	}
+	return nil, ErrNoPreview
+}
+
+// largest returns the biggest of the decoded thumbnails
+func largest(images []image.Image) image.Image {
+	var best image.Image
+	bestArea := 0
+	for _, img := range images {
+		bounds := img.Bounds()
+		if area := bounds.Dx() * bounds.Dy(); area > bestArea {
+			best, bestArea = img, area
+		}
+	}
+	return best
+}
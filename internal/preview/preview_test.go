@@ -0,0 +1,135 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"qslicerpicker/internal/mesh"
+	"strings"
+	"testing"
+)
+
+// encodedPNG returns a w×h PNG in base64
+func encodedPNG(t *testing.T, w, h int) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// thumbnailBlock embeds encoded data as comment lines like PrusaSlicer does
+func thumbnailBlock(keyword string, w, h int, encoded string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "; %s begin %dx%d %d\n", keyword, w, h, len(encoded))
+	for len(encoded) > 78 {
+		b.WriteString("; " + encoded[:78] + "\n")
+		encoded = encoded[78:]
+	}
+	b.WriteString("; " + encoded + "\n; " + keyword + " end\n;\n")
+	return b.String()
+}
+
+// writeGCode writes a G-code file with the given header
+func writeGCode(t *testing.T, header string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "model.gcode")
+	content := "; generated by PrusaSlicer 2.7.1+linux-x64-GTK3 on 2024-01-01 at 12:00:00 UTC\n\n" + header + "G28\nG1 X10 Y10\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestThumbnailGCodeChoosesLargest(t *testing.T) {
+	header := thumbnailBlock("thumbnail", 16, 16, encodedPNG(t, 16, 16)) +
+		thumbnailBlock("thumbnail_PNG", 220, 124, encodedPNG(t, 220, 124)) +
+		thumbnailBlock("thumbnail", 32, 32, encodedPNG(t, 32, 32))
+	img, err := ThumbnailGCode(writeGCode(t, header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size != image.Pt(220, 124) {
+		t.Errorf("thumbnail size = %v, want 220x124", size)
+	}
+}
+
+func TestThumbnailGCodeInvalid(t *testing.T) {
+	encoded := encodedPNG(t, 16, 16)
+	tests := map[string]string{
+		"truncated base64":  thumbnailBlock("thumbnail", 16, 16, encoded[:len(encoded)-7]),
+		"invalid base64":    thumbnailBlock("thumbnail", 16, 16, "not*base64!"),
+		"not an image":      thumbnailBlock("thumbnail", 16, 16, base64.StdEncoding.EncodeToString([]byte("G28"))),
+		"missing end":       "; thumbnail begin 16x16 100\n; " + encoded + "\n",
+		"end without begin": "; thumbnail end\n",
+		"after the G-code":  "G28\n" + thumbnailBlock("thumbnail", 16, 16, encoded),
+		"no thumbnail":      "; layer_height = 0.2\n",
+	}
+	for name, header := range tests {
+		if img, err := ThumbnailGCode(writeGCode(t, header)); !errors.Is(err, ErrNoPreview) {
+			t.Errorf("%s: ThumbnailGCode() = %v, %v, want ErrNoPreview", name, img, err)
+		}
+	}
+}
+
+func TestThumbnailGCodeSkipsInvalidBlocks(t *testing.T) {
+	header := thumbnailBlock("thumbnail", 300, 300, "not*base64!") +
+		thumbnailBlock("thumbnail", 16, 16, encodedPNG(t, 16, 16))
+	img, err := ThumbnailGCode(writeGCode(t, header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size != image.Pt(16, 16) {
+		t.Errorf("thumbnail size = %v, want 16x16", size)
+	}
+}
+
+func TestRender(t *testing.T) {
+	// A tetrahedron
+	m := &mesh.Mesh{
+		Vertices:  []mesh.Vec3{{0, 0, 0}, {10, 0, 0}, {0, 10, 0}, {0, 0, 10}},
+		Triangles: [][3]uint32{{0, 2, 1}, {0, 1, 3}, {0, 3, 2}, {1, 2, 3}},
+	}
+	for _, size := range []int{1, 64, 101} {
+		img := Render(m, size)
+		if got := img.Bounds().Size(); got != image.Pt(size, size) {
+			t.Errorf("Render(%d) size = %v", size, got)
+			continue
+		}
+		if opaque := opaquePixels(img); opaque == 0 {
+			t.Errorf("Render(%d) drew nothing", size)
+		} else if size > 1 && opaque == size*size {
+			t.Errorf("Render(%d) has no transparent background", size)
+		}
+	}
+}
+
+func TestRenderEmptyMesh(t *testing.T) {
+	img := Render(&mesh.Mesh{}, 32)
+	if got := img.Bounds().Size(); got != image.Pt(32, 32) {
+		t.Errorf("size = %v, want 32x32", got)
+	}
+	if opaque := opaquePixels(img); opaque != 0 {
+		t.Errorf("%d pixels drawn for an empty mesh", opaque)
+	}
+}
+
+// opaquePixels counts the pixels that are not fully transparent
+func opaquePixels(img image.Image) int {
+	count := 0
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0 {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,169 @@
+package preview
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"qslicerpicker/internal/mesh"
+)
+
+// View of rendered meshes: from the front right, looking down, for models
+// with Z up as slicers expect them
+const (
+	renderAzimuth   = -45 * math.Pi / 180
+	renderElevation = 30 * math.Pi / 180
+	renderMargin    = 0.06
+	supersampling   = 2
+	ambient         = 0.3
+	diffuse         = 0.7
+)
+
+var (
+	meshColor = color.RGBA{R: 0x4c, G: 0x8e, B: 0xda, A: 0xff}
+	lightDir  = normalize([3]float64{-0.4, 0.6, 1}) // view space: x right, y up, z to the viewer
+)
+
+// Render draws the mesh into a size×size image with a transparent
+// background, using an orthographic projection, a z-buffer and flat shading
+// lit from behind the viewer. Triangles are lit from both sides as the
+// winding of model files is not reliable.
+func Render(m *mesh.Mesh, size int) image.Image {
+	n := size * supersampling
+	img := image.NewRGBA(image.Rect(0, 0, n, n))
+	if len(m.Vertices) == 0 || size <= 0 {
+		return downsample(img, supersampling)
+	}
+
+	// Rotate into view space
+	sinA, cosA := math.Sincos(renderAzimuth)
+	sinE, cosE := math.Sincos(renderElevation)
+	projected := make([][3]float64, len(m.Vertices))
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i, v := range m.Vertices {
+		x := float64(v[0])*cosA - float64(v[1])*sinA
+		y := float64(v[0])*sinA + float64(v[1])*cosA
+		z := float64(v[2])
+		p := [3]float64{x, y*sinE + z*cosE, z*sinE - y*cosE}
+		projected[i] = p
+		minX, maxX = math.Min(minX, p[0]), math.Max(maxX, p[0])
+		minY, maxY = math.Min(minY, p[1]), math.Max(maxY, p[1])
+	}
+
+	// Fit the model into the image
+	extent := math.Max(math.Max(maxX-minX, maxY-minY), 1e-9)
+	scale := float64(n) * (1 - 2*renderMargin) / extent
+	centerX, centerY := (minX+maxX)/2, (minY+maxY)/2
+	for i, p := range projected {
+		projected[i] = [3]float64{
+			float64(n)/2 + (p[0]-centerX)*scale,
+			float64(n)/2 - (p[1]-centerY)*scale,
+			p[2],
+		}
+	}
+
+	depth := make([]float64, n*n)
+	for i := range depth {
+		depth[i] = math.Inf(-1)
+	}
+	for _, triangle := range m.Triangles {
+		a, b, c := projected[triangle[0]], projected[triangle[1]], projected[triangle[2]]
+		rasterize(img, depth, a, b, c, shade(a, b, c))
+	}
+	return downsample(img, supersampling)
+}
+
+// shade returns the flat colour of a triangle in image space
+func shade(a, b, c [3]float64) color.RGBA {
+	// Image y points down, so flip it back for the normal
+	u := [3]float64{b[0] - a[0], a[1] - b[1], b[2] - a[2]}
+	v := [3]float64{c[0] - a[0], a[1] - c[1], c[2] - a[2]}
+	normal := normalize([3]float64{
+		u[1]*v[2] - u[2]*v[1],
+		u[2]*v[0] - u[0]*v[2],
+		u[0]*v[1] - u[1]*v[0],
+	})
+	intensity := ambient + diffuse*math.Abs(normal[0]*lightDir[0]+normal[1]*lightDir[1]+normal[2]*lightDir[2])
+	return color.RGBA{
+		R: uint8(math.Min(255, float64(meshColor.R)*intensity)),
+		G: uint8(math.Min(255, float64(meshColor.G)*intensity)),
+		B: uint8(math.Min(255, float64(meshColor.B)*intensity)),
+		A: meshColor.A,
+	}
+}
+
+// rasterize fills the triangle where it is closer to the viewer than what
+// has been drawn; z grows towards the viewer
+func rasterize(img *image.RGBA, depth []float64, a, b, c [3]float64, fill color.RGBA) {
+	area := edge(a, b, c[0], c[1])
+	if area == 0 || math.IsNaN(area) || math.IsInf(area, 0) {
+		return
+	}
+
+	bounds := img.Bounds()
+	x0 := int(math.Max(math.Floor(math.Min(a[0], math.Min(b[0], c[0]))), 0))
+	x1 := int(math.Min(math.Ceil(math.Max(a[0], math.Max(b[0], c[0]))), float64(bounds.Dx()-1)))
+	y0 := int(math.Max(math.Floor(math.Min(a[1], math.Min(b[1], c[1]))), 0))
+	y1 := int(math.Min(math.Ceil(math.Max(a[1], math.Max(b[1], c[1]))), float64(bounds.Dy()-1)))
+
+	for y := y0; y <= y1; y++ {
+		py := float64(y) + 0.5
+		for x := x0; x <= x1; x++ {
+			px := float64(x) + 0.5
+			w0 := edge(b, c, px, py) / area
+			w1 := edge(c, a, px, py) / area
+			w2 := edge(a, b, px, py) / area
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+			z := w0*a[2] + w1*b[2] + w2*c[2]
+			i := y*bounds.Dx() + x
+			if z <= depth[i] {
+				continue
+			}
+			depth[i] = z
+			img.SetRGBA(x, y, fill)
+		}
+	}
+}
+
+// edge is the signed area of the parallelogram spanned by a→b and a→(x, y)
+func edge(a, b [3]float64, x, y float64) float64 {
+	return (b[0]-a[0])*(y-a[1]) - (b[1]-a[1])*(x-a[0])
+}
+
+// downsample averages factor×factor blocks to smooth the edges
+func downsample(src *image.RGBA, factor int) image.Image {
+	size := src.Bounds().Dx() / factor
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	samples := uint32(factor * factor)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			var r, g, b, a uint32
+			for sy := 0; sy < factor; sy++ {
+				for sx := 0; sx < factor; sx++ {
+					c := src.RGBAAt(x*factor+sx, y*factor+sy)
+					r += uint32(c.R)
+					g += uint32(c.G)
+					b += uint32(c.B)
+					a += uint32(c.A)
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / samples),
+				G: uint8(g / samples),
+				B: uint8(b / samples),
+				A: uint8(a / samples),
+			})
+		}
+	}
+	return dst
+}
+
+func normalize(v [3]float64) [3]float64 {
+	length := math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+	if length == 0 {
+		return v
+	}
+	return [3]float64{v[0] / length, v[1] / length, v[2] / length}
+}
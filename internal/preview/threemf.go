@@ -0,0 +1,52 @@
+package preview
+
+import (
+	"archive/zip"
+	"image"
+	"qslicerpicker/internal/threemf"
+)
+
+const maxThumbnailLen = 16 << 20
+
+// threeMFThumbnailParts are where slicers store the thumbnail when the
+// package relationships do not name one
+var threeMFThumbnailParts = []string{
+	"Metadata/thumbnail.png",
+	"Metadata/plate_1.png",
+	"Metadata/thumbnail.jpg",
+}
+
+// Thumbnail3MF returns the thumbnail of a 3MF package
+func Thumbnail3MF(filePath string) (image.Image, error) {
+	pkg, err := threemf.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+
+	parts := append([]string(nil), threeMFThumbnailParts...)
+	if target := pkg.RelationshipTarget(threemf.ThumbnailRelationship); target != "" {
+		parts = append([]string{target}, parts...)
+	}
+
+	for _, part := range parts {
+		file, ok := pkg.Parts[part]
+		if !ok || file.UncompressedSize64 > maxThumbnailLen {
+			continue
+		}
+		if img, err := decodePart(file); err == nil {
+			return img, nil
+		}
+	}
+	return nil, ErrNoPreview
+}
+
+func decodePart(file *zip.File) (image.Image, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	img, _, err := image.Decode(rc)
+	return img, err
+}
@@ -0,0 +1,142 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"qslicerpicker/internal/config"
+	"qslicerpicker/internal/slicer"
+	"strings"
+)
+
+// FileName is the project file looked up in a file's directory and its
+// ancestors
+const FileName = ".qslicerpicker.json"
+
+// Project holds slicer preferences shared by the models below a directory,
+// layered on top of the user configuration
+type Project struct {
+	// Dir is the directory containing the project file
+	Dir string `json:"-"`
+
+	// Slicer is the ID of the preferred slicer
+	Slicer string `json:"slicer,omitempty"`
+
+	// Arguments are added in front of a slicer's arguments, by slicer ID.
+	// {project} is replaced by the project directory, and arguments
+	// starting with ./ or ../ are resolved against it.
+	Arguments map[string]config.Arguments `json:"arguments,omitempty"`
+
+	// Order lists slicer IDs shown first in the selector, in this order
+	Order []string `json:"order,omitempty"`
+}
+
+// Find returns the project file in dir or the closest ancestor, or nil if
+// there is none
+func Find(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		proj, err := Load(filepath.Join(dir, FileName))
+		if err == nil {
+			return proj, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads a project file
+func Load(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	proj := &Project{}
+	if err := json.Unmarshal(data, proj); err != nil {
+		return nil, fmt.Errorf("invalid project file %s: %w", path, err)
+	}
+	proj.Dir = filepath.Dir(path)
+	return proj, nil
+}
+
+// ForFiles returns the project shared by all files, or nil if the files
+// belong to no project or to different ones
+func ForFiles(filePaths []string) (*Project, error) {
+	var shared *Project
+	for i, filePath := range filePaths {
+		proj, err := Find(filepath.Dir(filePath))
+		if err != nil || proj == nil {
+			return nil, err
+		}
+		if i > 0 && proj.Dir != shared.Dir {
+			return nil, nil
+		}
+		shared = proj
+	}
+	return shared, nil
+}
+
+// Apply returns the slicers with the project's arguments added and the
+// project's order applied. A nil project leaves them unchanged.
+func (p *Project) Apply(slicers []slicer.Slicer) []slicer.Slicer {
+	if p == nil {
+		return slicers
+	}
+
+	result := make([]slicer.Slicer, 0, len(slicers))
+	for _, s := range slicers {
+		extra, ok := p.Arguments[s.ID]
+		if !ok {
+			// Arguments for a slicer apply to all of its versions
+			extra = p.Arguments[s.BaseID()]
+		}
+		if len(extra) > 0 {
+			args := make([]string, 0, len(extra)+len(s.Arguments))
+			for _, arg := range extra {
+				args = append(args, p.expand(arg))
+			}
+			s.Arguments = append(args, s.Arguments...)
+		}
+		result = append(result, s)
+	}
+
+	// Slicers named in Order come first, the rest keep their order
+	ordered := make([]slicer.Slicer, 0, len(result))
+	placed := make(map[string]bool, len(p.Order))
+	for _, id := range p.Order {
+		for _, s := range result {
+			if s.ID == id && !placed[id] {
+				ordered = append(ordered, s)
+				placed[id] = true
+			}
+		}
+	}
+	for _, s := range result {
+		if !placed[s.ID] {
+			ordered = append(ordered, s)
+		}
+	}
+	return ordered
+}
+
+// expand substitutes {project} and resolves project-relative paths
+func (p *Project) expand(arg string) string {
+	arg = strings.ReplaceAll(arg, "{project}", p.Dir)
+	if strings.HasPrefix(arg, "./") || strings.HasPrefix(arg, "../") ||
+		strings.HasPrefix(arg, `.\`) || strings.HasPrefix(arg, `..\`) {
+		return filepath.Join(p.Dir, arg)
+	}
+	return arg
+}
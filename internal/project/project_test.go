@@ -0,0 +1,145 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"qslicerpicker/internal/slicer"
+	"reflect"
+	"testing"
+)
+
+// writeProject writes a project file into dir
+func writeProject(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	writeProject(t, filepath.Join(root, "printer"), `{"slicer": "prusaslicer"}`)
+	writeProject(t, filepath.Join(root, "printer", "parts", "brackets"), `{"slicer": "cura"}`)
+	if err := os.MkdirAll(filepath.Join(root, "printer", "parts", "fans", "40mm"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "unrelated"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir     string
+		wantDir string
+		slicer  string
+	}{
+		{"printer", "printer", "prusaslicer"},
+		{"printer/parts/fans/40mm", "printer", "prusaslicer"},
+		{"printer/parts/brackets", "printer/parts/brackets", "cura"},
+		{"unrelated", "", ""},
+	}
+	for _, tt := range tests {
+		proj, err := Find(filepath.Join(root, filepath.FromSlash(tt.dir)))
+		if err != nil {
+			t.Errorf("Find(%s): %v", tt.dir, err)
+			continue
+		}
+		if tt.wantDir == "" {
+			if proj != nil {
+				t.Errorf("Find(%s) = project in %s, want none", tt.dir, proj.Dir)
+			}
+			continue
+		}
+		if proj == nil || proj.Dir != filepath.Join(root, filepath.FromSlash(tt.wantDir)) || proj.Slicer != tt.slicer {
+			t.Errorf("Find(%s) = %+v, want %s in %s", tt.dir, proj, tt.slicer, tt.wantDir)
+		}
+	}
+}
+
+func TestFindInvalid(t *testing.T) {
+	root := t.TempDir()
+	writeProject(t, root, `{"slicer": `)
+	if _, err := Find(filepath.Join(root)); err == nil {
+		t.Error("Find() accepted an invalid project file")
+	}
+}
+
+func TestForFiles(t *testing.T) {
+	root := t.TempDir()
+	writeProject(t, filepath.Join(root, "a"), `{"slicer": "prusaslicer"}`)
+	writeProject(t, filepath.Join(root, "b"), `{"slicer": "cura"}`)
+	if err := os.MkdirAll(filepath.Join(root, "a", "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	a1 := filepath.Join(root, "a", "one.stl")
+	a2 := filepath.Join(root, "a", "sub", "two.stl")
+	b1 := filepath.Join(root, "b", "three.stl")
+
+	if proj, err := ForFiles([]string{a1, a2}); err != nil || proj == nil || proj.Slicer != "prusaslicer" {
+		t.Errorf("ForFiles() of one project = %+v, %v", proj, err)
+	}
+	if proj, err := ForFiles([]string{a1, b1}); err != nil || proj != nil {
+		t.Errorf("ForFiles() of two projects = %+v, %v, want none", proj, err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	root := t.TempDir()
+	writeProject(t, root, `{
+  "arguments": {
+    "prusaslicer": ["--load", "./profiles/petg.ini", "--datadir", "{project}/config"],
+    "cura@5.6.0": ["--debug"],
+    "orcaslicer": ["../shared.ini"]
+  },
+  "order": ["cura@5.6.0", "orcaslicer", "missing", "orcaslicer"]
+}`)
+	proj, err := Find(root)
+	if err != nil || proj == nil {
+		t.Fatalf("Find() = %v, %v", proj, err)
+	}
+
+	slicers := []slicer.Slicer{
+		{ID: "prusaslicer", Arguments: []string{"--single-instance"}},
+		{ID: "prusaslicer@2.7.1", Arguments: []string{"--single-instance"}},
+		{ID: "cura@5.6.0"},
+		{ID: "cura@5.7.0"},
+		{ID: "orcaslicer"},
+	}
+	got := proj.Apply(slicers)
+
+	wantIDs := []string{"cura@5.6.0", "orcaslicer", "prusaslicer", "prusaslicer@2.7.1", "cura@5.7.0"}
+	var ids []string
+	for _, s := range got {
+		ids = append(ids, s.ID)
+	}
+	if !reflect.DeepEqual(ids, wantIDs) {
+		t.Errorf("order = %q, want %q", ids, wantIDs)
+	}
+
+	prusaArgs := []string{"--load", filepath.Join(root, "profiles", "petg.ini"), "--datadir", root + "/config", "--single-instance"}
+	wantArgs := map[string][]string{
+		"prusaslicer":       prusaArgs,
+		"prusaslicer@2.7.1": prusaArgs, // falls back to the base ID
+		"cura@5.6.0":        {"--debug"},
+		"cura@5.7.0":        nil, // versions do not share arguments of another version
+		"orcaslicer":        {filepath.Join(filepath.Dir(root), "shared.ini")},
+	}
+	for _, s := range got {
+		if want := wantArgs[s.ID]; !reflect.DeepEqual(s.Arguments, want) {
+			t.Errorf("%s arguments = %q, want %q", s.ID, s.Arguments, want)
+		}
+	}
+	if !reflect.DeepEqual(slicers[0].Arguments, []string{"--single-instance"}) {
+		t.Errorf("Apply() changed the arguments it was given: %q", slicers[0].Arguments)
+	}
+}
+
+func TestApplyNil(t *testing.T) {
+	var proj *Project
+	slicers := []slicer.Slicer{{ID: "prusaslicer"}, {ID: "cura"}}
+	if got := proj.Apply(slicers); !reflect.DeepEqual(got, slicers) {
+		t.Errorf("nil project changed the slicers: %v", got)
+	}
+}
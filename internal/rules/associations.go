@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"path/filepath"
+	"qslicerpicker/internal/config"
+	"strings"
+)
+
+// MatchAssociation returns the remembered slicer for the files. Directory
+// associations win over extension associations, and all files must share
+// the association.
+func MatchAssociation(associations []config.Association, filePaths []string) (config.Association, bool) {
+	if len(filePaths) == 0 {
+		return config.Association{}, false
+	}
+
+	for _, byDirectory := range []bool{true, false} {
+		for _, association := range associations {
+			if association.Slicer == "" || (association.Directory != "") != byDirectory {
+				continue
+			}
+			matched := true
+			for _, filePath := range filePaths {
+				if !matchesAssociation(association, filePath) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				return association, true
+			}
+		}
+	}
+	return config.Association{}, false
+}
+
+func matchesAssociation(association config.Association, filePath string) bool {
+	if association.Directory != "" {
+		return inDirectory(filePath, association.Directory)
+	}
+	ext := strings.TrimPrefix(filepath.Ext(filePath), ".")
+	return association.Extension != "" && strings.EqualFold(association.Extension, ext)
+}
+
+// CommonExtension returns the extension, without the dot, shared by all files
+func CommonExtension(filePaths []string) string {
+	ext := ""
+	for i, filePath := range filePaths {
+		fileExt := strings.ToLower(strings.TrimPrefix(filepath.Ext(filePath), "."))
+		if fileExt == "" || (i > 0 && fileExt != ext) {
+			return ""
+		}
+		ext = fileExt
+	}
+	return ext
+}
+
+// CommonDirectory returns the directory containing all files
+func CommonDirectory(filePaths []string) string {
+	dir := ""
+	for i, filePath := range filePaths {
+		fileDir := filepath.Dir(filePath)
+		if i > 0 && fileDir != dir {
+			return ""
+		}
+		dir = fileDir
+	}
+	return dir
+}
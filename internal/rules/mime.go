@@ -0,0 +1,72 @@
+package rules
+
+import (
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// modelMIMETypes covers the formats the system MIME database rarely knows
+var modelMIMETypes = map[string]string{
+	"3mf":    "model/3mf",
+	"stl":    "model/stl",
+	"obj":    "model/obj",
+	"ply":    "model/ply",
+	"step":   "model/step",
+	"stp":    "model/step",
+	"amf":    "application/x-amf",
+	"usd":    "model/vnd.usd",
+	"usda":   "model/vnd.usd",
+	"usdc":   "model/vnd.usd",
+	"abc":    "application/x-abc",
+	"sla":    "application/x-sla",
+	"svg":    "image/svg+xml",
+	"gcode":  "text/x.gcode",
+	"bgcode": "application/x-bgcode",
+}
+
+// mimeAliases are other spellings of a MIME type that rules may use.
+// shared-mime-info calls G-code text/x.gcode, older tools text/x-gcode.
+var mimeAliases = map[string][]string{
+	"text/x.gcode": {"text/x-gcode"},
+}
+
+// matchesMIMEType reports whether the MIME type of a file, or one of its
+// aliases, matches a pattern such as "model/*"
+func matchesMIMEType(pattern, filePath string) bool {
+	pattern = strings.ToLower(pattern)
+	mimeType := MIMEType(filePath)
+	for _, name := range append([]string{mimeType}, mimeAliases[mimeType]...) {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// MIMEType returns the MIME type of a file from its extension, falling back
+// to sniffing its content
+func MIMEType(filePath string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filePath), "."))
+	if mimeType, ok := modelMIMETypes[ext]; ok {
+		return mimeType
+	}
+	if mimeType := mime.TypeByExtension("." + ext); ext != "" && mimeType != "" {
+		mimeType, _, _ = strings.Cut(mimeType, ";")
+		return mimeType
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, _ := file.Read(head)
+	mimeType, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+	return mimeType
+}
@@ -0,0 +1,152 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"qslicerpicker/internal/config"
+	"strconv"
+	"strings"
+)
+
+// Match returns the first enabled rule that matches every file
+func Match(rules []config.Rule, filePaths []string) (config.Rule, bool) {
+	if len(filePaths) == 0 {
+		return config.Rule{}, false
+	}
+	for _, rule := range rules {
+		if rule.Disabled || rule.Slicer == "" {
+			continue
+		}
+		matched := true
+		for _, filePath := range filePaths {
+			if !Matches(rule, filePath) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rule, true
+		}
+	}
+	return config.Rule{}, false
+}
+
+// Matches reports whether a single file satisfies every condition of the rule
+func Matches(rule config.Rule, filePath string) bool {
+	name := filepath.Base(filePath)
+
+	if len(rule.Extensions) > 0 {
+		ext := strings.TrimPrefix(filepath.Ext(name), ".")
+		found := false
+		for _, want := range rule.Extensions {
+			if strings.EqualFold(strings.TrimPrefix(want, "."), ext) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if rule.Glob != "" {
+		matched, err := filepath.Match(strings.ToLower(rule.Glob), strings.ToLower(name))
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	if rule.Directory != "" && !inDirectory(filePath, rule.Directory) {
+		return false
+	}
+
+	if rule.MinSize > 0 || rule.MaxSize > 0 {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return false
+		}
+		if rule.MinSize > 0 && info.Size() < rule.MinSize {
+			return false
+		}
+		if rule.MaxSize > 0 && info.Size() > rule.MaxSize {
+			return false
+		}
+	}
+
+	if rule.MIMEType != "" && !matchesMIMEType(rule.MIMEType, filePath) {
+		return false
+	}
+
+	return true
+}
+
+// inDirectory reports whether filePath is inside dir or one of its
+// subdirectories. "~" and environment variables in dir are expanded.
+func inDirectory(filePath string, dir string) bool {
+	dir = os.ExpandEnv(dir)
+	if dir == "~" || strings.HasPrefix(dir, "~/") || strings.HasPrefix(dir, `~\`) {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(homeDir, dir[1:])
+		}
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absFile, err := filepath.Abs(filePath)
+	if err != nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(absDir, filepath.Dir(absFile))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// ParseSize parses a file size such as "512", "20K", "1.5MB" or "2 GiB"
+func ParseSize(text string) (int64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+
+	upper := strings.ToUpper(text)
+	upper = strings.TrimSuffix(strings.TrimSuffix(upper, "IB"), "B")
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		value  int64
+	}{{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}} {
+		if strings.HasSuffix(upper, unit.suffix) {
+			upper = strings.TrimSuffix(upper, unit.suffix)
+			multiplier = unit.value
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(upper), 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", text)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// FormatSize formats a size in the largest unit that keeps it a whole number
+func FormatSize(size int64) string {
+	if size <= 0 {
+		return ""
+	}
+	for _, unit := range []struct {
+		suffix string
+		value  int64
+	}{{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}} {
+		if size%unit.value == 0 {
+			return strconv.FormatInt(size/unit.value, 10) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(size, 10)
+}
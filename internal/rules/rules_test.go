@@ -0,0 +1,131 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"qslicerpicker/internal/config"
+	"strings"
+	"testing"
+)
+
+// writeFile creates a file of the given size below dir
+func writeFile(t *testing.T, dir, name string, size int) string {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMatch(t *testing.T) {
+	dir := t.TempDir()
+	small := writeFile(t, dir, "prints/Benchy_PLA.3mf", 100)
+	large := writeFile(t, dir, "prints/big.stl", 3<<10)
+	gcode := writeFile(t, dir, "out/part.gcode", 10)
+
+	tests := []struct {
+		name  string
+		rules []config.Rule
+		files []string
+		want  string // matching slicer, "" for none
+	}{
+		{"extension", []config.Rule{{Extensions: []string{"gcode", "bgcode"}, Slicer: "viewer"}}, []string{gcode}, "viewer"},
+		{"extension with dot and case", []config.Rule{{Extensions: []string{".3MF"}, Slicer: "orca"}}, []string{small}, "orca"},
+		{"glob ignores case", []config.Rule{{Glob: "*_pla.3mf", Slicer: "orca"}}, []string{small}, "orca"},
+		{"glob mismatch", []config.Rule{{Glob: "*_petg.3mf", Slicer: "orca"}}, []string{small}, ""},
+		{"invalid glob", []config.Rule{{Glob: "[", Slicer: "orca"}}, []string{small}, ""},
+		{"directory", []config.Rule{{Directory: filepath.Join(dir, "prints"), Slicer: "prusa"}}, []string{small, large}, "prusa"},
+		{"directory must hold all files", []config.Rule{{Directory: filepath.Join(dir, "prints"), Slicer: "prusa"}}, []string{small, gcode}, ""},
+		{"min size", []config.Rule{{MinSize: 1 << 10, Slicer: "cura"}}, []string{large}, "cura"},
+		{"min size too small", []config.Rule{{MinSize: 1 << 10, Slicer: "cura"}}, []string{small}, ""},
+		{"max size", []config.Rule{{MaxSize: 1 << 10, Slicer: "cura"}}, []string{small}, "cura"},
+		{"size of missing file", []config.Rule{{MaxSize: 1 << 10, Slicer: "cura"}}, []string{filepath.Join(dir, "missing.stl")}, ""},
+		{"MIME pattern", []config.Rule{{MIMEType: "model/*", Slicer: "prusa"}}, []string{small, large}, "prusa"},
+		{"G-code MIME type", []config.Rule{{MIMEType: "text/x.gcode", Slicer: "viewer"}}, []string{gcode}, "viewer"},
+		{"G-code MIME alias", []config.Rule{{MIMEType: "text/x-gcode", Slicer: "viewer"}}, []string{gcode}, "viewer"},
+		{"all files must match", []config.Rule{{Extensions: []string{"3mf"}, Slicer: "orca"}}, []string{small, large}, ""},
+		{"first match wins", []config.Rule{{Extensions: []string{"stl"}, Slicer: "cura"}, {Slicer: "prusa"}}, []string{large}, "cura"},
+		{"disabled rule", []config.Rule{{Disabled: true, Slicer: "cura"}, {Slicer: "prusa"}}, []string{large}, "prusa"},
+		{"rule without slicer", []config.Rule{{Extensions: []string{"stl"}}}, []string{large}, ""},
+		{"no files", []config.Rule{{Slicer: "prusa"}}, nil, ""},
+	}
+	for _, tt := range tests {
+		rule, ok := Match(tt.rules, tt.files)
+		if ok != (tt.want != "") || rule.Slicer != tt.want {
+			t.Errorf("%s: Match = %q, %v; want %q", tt.name, rule.Slicer, ok, tt.want)
+		}
+	}
+}
+
+func TestInDirectory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("MODELS", filepath.Join(home, "Models"))
+	file := filepath.Join(home, "Models", "Printables", "cube.stl")
+
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{filepath.Join(home, "Models"), true},
+		{filepath.Join(home, "Models", "Printables"), true},
+		{filepath.Join(home, "Models") + string(filepath.Separator), true},
+		{"~", true},
+		{"~/Models", true},
+		{"$MODELS", true},
+		{"${MODELS}/Printables", true},
+		{"~/Downloads", false},
+		{filepath.Join(home, "Mod"), false},
+		{filepath.Join(home, "Models", "Printables", "cube.stl"), false},
+	}
+	for _, tt := range tests {
+		if got := inDirectory(file, tt.dir); got != tt.want {
+			t.Errorf("inDirectory(%q, %q) = %v, want %v", file, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		text string
+		want int64
+	}{
+		{"", 0},
+		{"512", 512},
+		{"512B", 512},
+		{"20K", 20 << 10},
+		{"20kb", 20 << 10},
+		{"1.5MB", 3 << 19},
+		{"2 GiB", 2 << 30},
+		{" 3 M ", 3 << 20},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.text)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", tt.text, got, err, tt.want)
+		}
+	}
+
+	for _, text := range []string{"abc", "-1K", "1T", "K"} {
+		if got, err := ParseSize(text); err == nil {
+			t.Errorf("ParseSize(%q) = %d, want an error", text, got)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for _, size := range []int64{512, 20 << 10, 3 << 20, 2 << 30, 1536} {
+		got, err := ParseSize(FormatSize(size))
+		if err != nil || got != size {
+			t.Errorf("ParseSize(FormatSize(%d)) = %d, %v", size, got, err)
+		}
+	}
+	if got := FormatSize(0); got != "" {
+		t.Errorf("FormatSize(0) = %q, want empty", got)
+	}
+}
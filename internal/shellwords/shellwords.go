@@ -0,0 +1,113 @@
+package shellwords
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Split parses a command line into arguments using POSIX shell quoting rules:
+// single quotes preserve everything literally, double quotes allow \", \\, \$
+// and \` escapes, and a backslash outside quotes escapes the next character.
+func Split(line string) ([]string, error) {
+	args := make([]string, 0)
+	var current strings.Builder
+	inArg := false
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			i++
+			// Backslash-newline is a line continuation
+			if runes[i] != '\n' {
+				current.WriteRune(runes[i])
+				inArg = true
+			}
+
+		case r == '\'':
+			inArg = true
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+
+		case r == '"':
+			inArg = true
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					switch runes[i+1] {
+					case '"', '\\', '$', '`':
+						i++
+					case '\n':
+						i++
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// Quote returns arg quoted so that Split parses it back as a single argument
+func Quote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	if !needsQuoting(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// Join quotes each argument and joins them with spaces
+func Join(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = Quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func needsQuoting(arg string) bool {
+	for _, r := range arg {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("-_./:=,+@%{}", r):
+		default:
+			return true
+		}
+	}
+	return false
+}
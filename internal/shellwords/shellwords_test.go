@@ -0,0 +1,93 @@
+package shellwords
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", []string{}},
+		{"   ", []string{}},
+		{"--a --b", []string{"--a", "--b"}},
+		{" \t--a\n --b ", []string{"--a", "--b"}},
+		{`--datadir 'My Profiles'`, []string{"--datadir", "My Profiles"}},
+		{`"a b" c`, []string{"a b", "c"}},
+		{`'it'\''s'`, []string{"it's"}},
+		{`"say \"hi\""`, []string{`say "hi"`}},
+		{`"\$HOME \\ \a"`, []string{`$HOME \ \a`}},
+		{`'\n $x "'`, []string{`\n $x "`}},
+		{`a\ b`, []string{"a b"}},
+		{"a\\\nb", []string{"ab"}},
+		{`''`, []string{""}},
+		{`"" x`, []string{"", "x"}},
+		{`pre"mid"'post'`, []string{"premidpost"}},
+		{`{file} --x={files}`, []string{"{file}", "--x={files}"}},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.line)
+		if err != nil {
+			t.Errorf("Split(%q) error: %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Split(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	for _, line := range []string{
+		`'unterminated`,
+		`"unterminated`,
+		`a "b`,
+		`trailing\`,
+		`"escaped end\"`,
+	} {
+		if args, err := Split(line); err == nil {
+			t.Errorf("Split(%q) = %q, want an error", line, args)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"", "''"},
+		{"--layer-height=0.2", "--layer-height=0.2"},
+		{"{files}", "{files}"},
+		{"My Profiles", "'My Profiles'"},
+		{"it's", `'it'\''s'`},
+		{`$HOME`, `'$HOME'`},
+	}
+	for _, tt := range tests {
+		if got := Quote(tt.arg); got != tt.want {
+			t.Errorf("Quote(%q) = %q, want %q", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestJoinRoundTrip(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"--a", "b"},
+		{"", "x", ""},
+		{"My Profiles", "it's", `say "hi"`, `back\slash`, "$HOME", "tab\there", "new\nline"},
+		{"'", `"`, `\`, "`", "日本語 ファイル"},
+	} {
+		line := Join(args)
+		got, err := Split(line)
+		if err != nil {
+			t.Errorf("Split(Join(%q)) = %q: %v", args, line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, args) {
+			t.Errorf("Split(Join(%q)) = %q via %q", args, got, line)
+		}
+	}
+}
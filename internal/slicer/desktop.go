@@ -0,0 +1,262 @@
+package slicer
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"qslicerpicker/internal/config"
+	"qslicerpicker/internal/desktopentry"
+	"strings"
+)
+
+// DesktopApp is an application from an XDG .desktop entry that can be
+// imported as a slicer
+type DesktopApp struct {
+	Entry       *desktopentry.Entry
+	ID          string // the default slicer it starts, "" for other applications
+	Name        string
+	Path        string
+	FlatpakID   string
+	Arguments   []string // Exec arguments with field codes turned into placeholders
+	SingleFile  bool     // Exec takes one file per process (%f or %u)
+	Environment []config.EnvVar
+}
+
+// modelMIMETypes mark an application as a candidate slicer
+var modelMIMETypes = map[string]bool{
+	"model/stl":                      true,
+	"model/x.stl-ascii":              true,
+	"model/x.stl-binary":             true,
+	"application/sla":                true,
+	"model/3mf":                      true,
+	"application/vnd.ms-3mfdocument": true,
+	"application/vnd.ms-package.3dmanufacturing-3dmodel+xml": true,
+}
+
+// DesktopApps returns the installed applications that are slicers: entries
+// that start a default slicer and other applications that open STL or 3MF
+// models. The picker's own entry is left out.
+func DesktopApps() []DesktopApp {
+	self, _ := os.Executable()
+	var apps []DesktopApp
+	for _, entry := range desktopentry.All() {
+		if entry.Hidden || filepath.Base(entry.Path) == "qslicerpicker.desktop" {
+			continue
+		}
+		if entry.TryExec != "" {
+			if _, err := exec.LookPath(entry.TryExec); err != nil {
+				continue
+			}
+		}
+
+		app, err := NewDesktopApp(entry)
+		if err != nil || (self != "" && app.Path != "" && SamePath(app.Path, self)) {
+			continue
+		}
+		if app.ID != "" {
+			// The G-code viewer entry of a slicer starts the same slicer
+			if viewer := defaultViewerArguments[app.ID]; len(viewer) > 0 && containsAll(app.Arguments, viewer) {
+				continue
+			}
+		} else if !opensModels(entry) {
+			continue
+		}
+		apps = append(apps, app)
+	}
+	return apps
+}
+
+// NewDesktopApp converts the Exec line of an entry into a command. Flatpak
+// apps started with "flatpak run <app-id>" get their app ID, and an
+// "env VAR=value" prefix becomes environment overrides.
+func NewDesktopApp(entry *desktopentry.Entry) (DesktopApp, error) {
+	args, err := entry.Args()
+	if err != nil {
+		return DesktopApp{}, err
+	}
+
+	app := DesktopApp{Entry: entry, Name: entry.Name}
+	if len(args) > 0 && args[0] == "env" {
+		args = args[1:]
+		for len(args) > 0 && strings.Contains(args[0], "=") && !strings.HasPrefix(args[0], "-") {
+			name, value, _ := strings.Cut(args[0], "=")
+			app.Environment = append(app.Environment, config.EnvVar{Name: name, Value: value})
+			args = args[1:]
+		}
+	}
+	if len(args) == 0 {
+		return DesktopApp{}, errors.New("no command in Exec")
+	}
+
+	if filepath.Base(args[0]) == "flatpak" && len(args) > 1 && args[1] == "run" {
+		// Options come before the app ID and are always --name=value
+		rest := args[2:]
+		for len(rest) > 0 && strings.HasPrefix(rest[0], "-") {
+			rest = rest[1:]
+		}
+		if len(rest) == 0 {
+			return DesktopApp{}, errors.New("no app ID in flatpak run")
+		}
+		app.FlatpakID = rest[0]
+		args = rest[1:]
+	} else {
+		app.Path = args[0]
+		if !filepath.IsAbs(app.Path) {
+			if path, err := exec.LookPath(app.Path); err == nil {
+				app.Path = path
+			}
+		}
+		args = args[1:]
+	}
+
+	app.Arguments, app.SingleFile = ExpandFieldCodes(args)
+	app.ID = knownSlicer(entry, app)
+	if app.ID == "" && app.Name == "" {
+		app.Name = strings.TrimSuffix(filepath.Base(entry.Path), ".desktop")
+	}
+	return app, nil
+}
+
+// ExpandFieldCodes turns the field codes of Exec arguments into argument
+// placeholders: %f and %u become {file}, %F and %U become {files} and %%
+// becomes %. Other codes, such as %i, %c and %k, are dropped, as are
+// the @@ markers of "flatpak run --file-forwarding". single reports whether
+// the application takes one file per process, which %f and %u mean.
+func ExpandFieldCodes(args []string) (result []string, single bool) {
+	result = make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "@@" || arg == "@@u" {
+			continue
+		}
+		var b strings.Builder
+		for i := 0; i < len(arg); i++ {
+			if arg[i] != '%' || i+1 == len(arg) {
+				b.WriteByte(arg[i])
+				continue
+			}
+			i++
+			switch arg[i] {
+			case 'f', 'u':
+				b.WriteString("{file}")
+				single = true
+			case 'F', 'U':
+				b.WriteString("{files}")
+			case '%':
+				b.WriteByte('%')
+			}
+		}
+		// An argument that was only a dropped field code goes away
+		if b.Len() > 0 || arg == "" {
+			result = append(result, b.String())
+		}
+	}
+	return result, single
+}
+
+// Discovered reports whether discovery finds the application as an
+// installation of its default slicer, so that it needs no custom path
+func (app DesktopApp) Discovered() bool {
+	if app.ID == "" {
+		return false
+	}
+	for _, inst := range Discover(app.ID) {
+		if app.FlatpakID != "" {
+			if inst.FlatpakID == app.FlatpakID {
+				return true
+			}
+		} else if inst.FlatpakID == "" && SamePath(inst.Path, app.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// knownSlicer returns the ID of the default slicer the entry starts, matched
+// by Flatpak app ID, command, AppImage name, Name or StartupWMClass
+func knownSlicer(entry *desktopentry.Entry, app DesktopApp) string {
+	command := strings.ToLower(filepath.Base(app.Path))
+	command = strings.TrimSuffix(strings.TrimSuffix(command, ".exe"), ".appimage")
+	for _, ds := range defaultSlicers {
+		spec := searchSpecs[ds.ID]
+		if app.FlatpakID != "" {
+			if strings.EqualFold(app.FlatpakID, spec.FlatpakID) {
+				return ds.ID
+			}
+			continue
+		}
+		for _, name := range spec.Commands {
+			if command == strings.ToLower(name) {
+				return ds.ID
+			}
+		}
+		for _, pattern := range spec.AppImages {
+			if ok, _ := filepath.Match(pattern, filepath.Base(app.Path)); ok {
+				return ds.ID
+			}
+		}
+	}
+
+	// Names are compared without case, spaces and punctuation so that
+	// "Bambu Studio" matches "BambuStudio"
+	name := normalizeName(entry.Name)
+	wmClass := normalizeName(entry.StartupWMClass)
+	for _, ds := range defaultSlicers {
+		known := normalizeName(ds.Name)
+		if name == known || wmClass == known || wmClass == ds.ID {
+			return ds.ID
+		}
+	}
+	return ""
+}
+
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, name)
+}
+
+// opensModels reports whether the entry declares an STL or 3MF MIME type
+func opensModels(entry *desktopentry.Entry) bool {
+	for _, mimeType := range entry.MimeType {
+		if modelMIMETypes[strings.ToLower(mimeType)] {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(args, wanted []string) bool {
+	for _, w := range wanted {
+		found := false
+		for _, arg := range args {
+			if arg == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// SamePath compares paths after resolving symbolic links
+func SamePath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	resolvedA, errA := filepath.EvalSymlinks(a)
+	resolvedB, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && resolvedA == resolvedB
+}
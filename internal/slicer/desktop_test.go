@@ -0,0 +1,27 @@
+package slicer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandFieldCodes(t *testing.T) {
+	tests := []struct {
+		args   []string
+		want   []string
+		single bool
+	}{
+		{[]string{"%F"}, []string{"{files}"}, false},
+		{[]string{"--single-instance", "%U"}, []string{"--single-instance", "{files}"}, false},
+		{[]string{"%f"}, []string{"{file}"}, true},
+		{[]string{"--load=%u", "%i"}, []string{"--load={file}"}, true},
+		{[]string{"--file-forwarding", "@@", "%F", "@@"}, []string{"--file-forwarding", "{files}"}, false},
+		{[]string{"--scale=100%%", "%c", ""}, []string{"--scale=100%", ""}, false},
+	}
+	for _, tt := range tests {
+		got, single := ExpandFieldCodes(tt.args)
+		if !reflect.DeepEqual(got, tt.want) || single != tt.single {
+			t.Errorf("ExpandFieldCodes(%q) = %q, %v; want %q, %v", tt.args, got, single, tt.want, tt.single)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package slicer
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts the slicer in its own session so it survives the picker
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,367 @@
+package slicer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Installation sources reported by Discover
+const (
+	SourceDefault  = "default"  // the slicer's built-in default path
+	SourceLocation = "location" // a known install location or glob
+	SourcePATH     = "path"     // an executable found on $PATH
+	SourceAppImage = "appimage" // an AppImage in a common download location
+)
+
+// Installation is a slicer installation found on disk
+type Installation struct {
+	Path      string
+	Source    string
+	FlatpakID string // set for Flatpak installations, launched via "flatpak run"
+	Version   string // set by DetectVersions, "" if unknown
+}
+
+// searchSpec describes where a slicer is usually installed
+type searchSpec struct {
+	Commands  []string            // executable names looked up on $PATH and in common bin directories
+	AppImages []string            // AppImage file name globs (Linux only)
+	FlatpakID string              // Flathub application ID (Linux only)
+	Locations map[string][]string // platform -> additional glob patterns
+}
+
+var searchSpecs = map[string]searchSpec{
+	"cura": {
+		Commands:  []string{"cura", "cura-slicer", "ultimaker-cura"},
+		AppImages: []string{"Ulti[Mm]aker-Cura-*.AppImage", "Ulti[Mm]aker_Cura-*.AppImage"},
+		FlatpakID: "com.ultimaker.cura",
+		Locations: map[string][]string{
+			"darwin": {
+				"/Applications/UltiMaker Cura.app/Contents/MacOS/UltiMaker-Cura",
+				"/Applications/Ultimaker Cura*.app/Contents/MacOS/Ultimaker Cura",
+				"~/Applications/Ultimaker Cura*.app/Contents/MacOS/Ultimaker Cura",
+			},
+			"windows": {
+				"${ProgramFiles}\\UltiMaker Cura *\\UltiMaker-Cura.exe",
+				"${ProgramFiles}\\Ultimaker Cura *\\Ultimaker-Cura.exe",
+			},
+			"linux": {
+				"/opt/cura/cura",
+				"/opt/ultimaker-cura/UltiMaker-Cura",
+			},
+		},
+	},
+	"prusaslicer": {
+		Commands:  []string{"prusa-slicer", "PrusaSlicer"},
+		AppImages: []string{"PrusaSlicer-*.AppImage"},
+		FlatpakID: "com.prusa3d.PrusaSlicer",
+		Locations: map[string][]string{
+			"darwin": {
+				"/Applications/PrusaSlicer*.app/Contents/MacOS/PrusaSlicer",
+				"~/Applications/PrusaSlicer*.app/Contents/MacOS/PrusaSlicer",
+			},
+			"windows": {
+				"${ProgramFiles}\\Prusa3D\\PrusaSlicer\\prusa-slicer.exe",
+				"${LOCALAPPDATA}\\Programs\\PrusaSlicer\\prusa-slicer.exe",
+			},
+			"linux": {
+				"/opt/PrusaSlicer*/prusa-slicer",
+				"/opt/prusa-slicer/prusa-slicer",
+			},
+		},
+	},
+	"superslicer": {
+		Commands:  []string{"superslicer", "super-slicer", "SuperSlicer"},
+		AppImages: []string{"SuperSlicer*.AppImage"},
+		FlatpakID: "com.superslicer.SuperSlicer",
+		Locations: map[string][]string{
+			"darwin": {
+				"~/Applications/SuperSlicer*.app/Contents/MacOS/SuperSlicer",
+			},
+			"windows": {
+				"${ProgramFiles}\\SuperSlicer*\\superslicer.exe",
+				"${LOCALAPPDATA}\\Programs\\SuperSlicer\\superslicer.exe",
+			},
+			"linux": {
+				"/opt/SuperSlicer*/superslicer",
+			},
+		},
+	},
+	"orcaslicer": {
+		Commands:  []string{"orca-slicer", "orcaslicer", "OrcaSlicer"},
+		AppImages: []string{"OrcaSlicer*.AppImage"},
+		FlatpakID: "io.github.softfever.OrcaSlicer",
+		Locations: map[string][]string{
+			"darwin": {
+				"~/Applications/OrcaSlicer*.app/Contents/MacOS/OrcaSlicer",
+			},
+			"windows": {
+				"${LOCALAPPDATA}\\Programs\\OrcaSlicer\\orca-slicer.exe",
+				"${ProgramFiles}\\OrcaSlicer\\orca-slicer.exe",
+			},
+			"linux": {
+				"/opt/OrcaSlicer*/bin/orca-slicer",
+				"/opt/orca-slicer/orca-slicer",
+			},
+		},
+	},
+	"bambustudio": {
+		Commands:  []string{"bambu-studio", "bambustudio", "BambuStudio"},
+		AppImages: []string{"Bambu_Studio*.AppImage", "BambuStudio*.AppImage"},
+		FlatpakID: "com.bambulab.BambuStudio",
+		Locations: map[string][]string{
+			"darwin": {
+				"~/Applications/BambuStudio*.app/Contents/MacOS/BambuStudio",
+			},
+			"windows": {
+				"${ProgramFiles}\\Bambu Studio\\bambu-studio.exe",
+				"${LOCALAPPDATA}\\Programs\\Bambu Studio\\bambu-studio.exe",
+			},
+			"linux": {
+				"/opt/bambu-studio/bin/bambu-studio",
+			},
+		},
+	},
+	"slic3r": {
+		Commands:  []string{"slic3r", "Slic3r"},
+		AppImages: []string{"Slic3r-*.AppImage"},
+		Locations: map[string][]string{
+			"darwin": {
+				"~/Applications/Slic3r.app/Contents/MacOS/Slic3r",
+			},
+			"linux": {
+				"/opt/Slic3r*/Slic3r",
+			},
+		},
+	},
+	"ideamaker": {
+		Commands: []string{"ideamaker", "ideaMaker"},
+		Locations: map[string][]string{
+			"darwin": {
+				"/Applications/ideaMaker.app/Contents/MacOS/ideaMaker",
+				"~/Applications/ideaMaker.app/Contents/MacOS/ideaMaker",
+			},
+			"windows": {
+				"${ProgramFiles}\\Raise3D\\ideaMaker\\ideaMaker.exe",
+			},
+			"linux": {
+				"/usr/lib/x86_64-linux-gnu/ideamaker/ideamaker",
+			},
+		},
+	},
+	"simplify3d": {
+		Commands: []string{"simplify3d", "Simplify3D"},
+		Locations: map[string][]string{
+			"darwin": {
+				"/Applications/Simplify3D*/Simplify3D.app/Contents/MacOS/Simplify3D",
+			},
+			"windows": {
+				"${ProgramFiles}\\Simplify3D*\\Simplify3D.exe",
+			},
+			"linux": {
+				"/opt/Simplify3D*/Simplify3D",
+				"/opt/Simplify3D*/LaunchScript.sh",
+			},
+		},
+	},
+	"kisslicer": {
+		Commands:  []string{"kisslicer", "KISSlicer"},
+		AppImages: []string{"KISSlicer*.AppImage"},
+		Locations: map[string][]string{
+			"windows": {
+				"${ProgramFiles}\\KISSlicer*\\KISSlicer*.exe",
+			},
+			"linux": {
+				"/opt/KISSlicer*/KISSlicer*",
+			},
+		},
+	},
+	"slic3rpe": {
+		Commands:  []string{"slic3r-pe", "slic3r-prusa3d"},
+		AppImages: []string{"Slic3rPE-*.AppImage"},
+		Locations: map[string][]string{
+			"windows": {
+				"${ProgramFiles}\\Prusa3D\\Slic3rPE\\slic3r-console.exe",
+			},
+		},
+	},
+}
+
+// Directories probed for Commands in addition to $PATH (Linux only)
+var linuxBinDirs = []string{
+	"/usr/bin",
+	"/usr/local/bin",
+	"~/.local/bin",
+	"~/bin",
+	"/snap/bin",
+}
+
+// Directories probed for AppImages (Linux only)
+var appImageDirs = []string{
+	"~/Applications",
+	"~/.local/bin",
+	"~/AppImages",
+	"~/.local/share/AppImage",
+	"/opt",
+	"/opt/AppImages",
+}
+
+// Discover probes every known location of the slicer with the given ID and
+// returns all installations found, best candidate first
+func Discover(id string) []Installation {
+	platform := runtime.GOOS
+	found := make([]Installation, 0)
+	seen := make(map[string]bool)
+
+	add := func(path, source string) {
+		if !isExecutableFile(path) {
+			return
+		}
+		key := path
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			key = resolved
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		found = append(found, Installation{
+			Path:      path,
+			Source:    source,
+			FlatpakID: flatpakIDFromPath(path),
+		})
+	}
+
+	// The built-in default path always comes first
+	for _, ds := range defaultSlicers {
+		if ds.ID == id && ds.DefaultPath[platform] != "" {
+			add(ds.DefaultPath[platform], SourceDefault)
+		}
+	}
+
+	spec, ok := searchSpecs[id]
+	if !ok {
+		return found
+	}
+
+	for _, pattern := range spec.Locations[platform] {
+		for _, path := range globPattern(pattern) {
+			add(path, SourceLocation)
+		}
+	}
+
+	for _, command := range spec.Commands {
+		if path, err := exec.LookPath(command); err == nil {
+			if abs, err := filepath.Abs(path); err == nil {
+				path = abs
+			}
+			add(path, SourcePATH)
+		}
+		if platform == "linux" {
+			for _, dir := range linuxBinDirs {
+				add(filepath.Join(expandPath(dir), command), SourcePATH)
+			}
+		}
+	}
+
+	if platform == "linux" {
+		for _, dir := range appImageDirs {
+			for _, name := range spec.AppImages {
+				for _, path := range globPattern(filepath.Join(dir, name)) {
+					add(path, SourceAppImage)
+				}
+			}
+		}
+
+		if deployDir, ok := FindFlatpak(spec.FlatpakID); ok {
+			found = append(found, Installation{
+				Path:      deployDir,
+				Source:    SourceFlatpak,
+				FlatpakID: spec.FlatpakID,
+			})
+		}
+	}
+
+	return found
+}
+
+// DiscoverAll runs Discover for every default slicer
+func DiscoverAll() map[string][]Installation {
+	result := make(map[string][]Installation, len(defaultSlicers))
+	for _, ds := range defaultSlicers {
+		result[ds.ID] = Discover(ds.ID)
+	}
+	return result
+}
+
+// globPattern expands ~ and environment variables and returns the matching
+// paths, latest first so that versioned file names prefer the latest release
+func globPattern(pattern string) []string {
+	pattern = expandPath(pattern)
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil
+	}
+	sortLatestFirst(matches)
+	return matches
+}
+
+// sortLatestFirst sorts paths by the versions in them, newest first, falling
+// back to the modification time and then to the name. Paths without a
+// version come last, as their age says nothing about the release.
+func sortLatestFirst(paths []string) {
+	versions := make(map[string][]string, len(paths))
+	modTimes := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		versions[path] = versionPattern.FindAllString(path, -1)
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		}
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		a, b := paths[i], paths[j]
+		va, vb := versions[a], versions[b]
+		if (len(va) == 0) != (len(vb) == 0) {
+			return len(va) > 0
+		}
+		for k := 0; k < len(va) && k < len(vb); k++ {
+			if c := compareVersions(va[k], vb[k]); c != 0 {
+				return c > 0
+			}
+		}
+		if !modTimes[a].Equal(modTimes[b]) {
+			return modTimes[a].After(modTimes[b])
+		}
+		return a > b
+	})
+}
+
+// expandPath expands a leading ~ and environment variables in a path
+func expandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~\\") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, path[1:])
+		}
+	}
+	return os.ExpandEnv(path)
+}
+
+// isExecutableFile reports whether path is a regular file that can be run
+func isExecutableFile(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode()&0111 != 0
+}
@@ -0,0 +1,53 @@
+package slicer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGlobPatternLatestFirst(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"PrusaSlicer-2.9.0+linux-x64.AppImage",
+		"PrusaSlicer-2.10.0+linux-x64.AppImage",
+		"PrusaSlicer-2.10.0-rc1+linux-x64.AppImage",
+		"PrusaSlicer-2.8.1+linux-x64.AppImage",
+		"PrusaSlicer-nightly.AppImage",
+		"PrusaSlicer.AppImage",
+	}
+	now := time.Now()
+	for i, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+		// Unversioned names are ordered by age and come after the
+		// versioned ones even when they are newer
+		modTime := now.Add(time.Duration(i) * time.Hour)
+		if name == "PrusaSlicer.AppImage" {
+			modTime = now.Add(-time.Hour)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		"PrusaSlicer-2.10.0+linux-x64.AppImage",
+		"PrusaSlicer-2.10.0-rc1+linux-x64.AppImage",
+		"PrusaSlicer-2.9.0+linux-x64.AppImage",
+		"PrusaSlicer-2.8.1+linux-x64.AppImage",
+		"PrusaSlicer-nightly.AppImage",
+		"PrusaSlicer.AppImage",
+	}
+	var got []string
+	for _, path := range globPattern(filepath.Join(dir, "PrusaSlicer*.AppImage")) {
+		got = append(got, filepath.Base(path))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("globPattern() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,166 @@
+package slicer
+
+import (
+	"fmt"
+	"os"
+	"qslicerpicker/internal/config"
+	"runtime"
+	"strings"
+)
+
+// ParseEnvironment parses environment overrides, one per line:
+//
+//	NAME=value    set NAME
+//	NAME+=value   prepend value to the path list in NAME
+//	-NAME         unset NAME
+//
+// Empty lines and lines starting with # are ignored.
+func ParseEnvironment(text string) ([]config.EnvVar, error) {
+	vars := make([]config.EnvVar, 0)
+
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "-") {
+			name := strings.TrimSpace(line[1:])
+			if !validEnvName(name) {
+				return nil, fmt.Errorf("line %d: invalid variable name %q", i+1, name)
+			}
+			vars = append(vars, config.EnvVar{Name: name, Mode: config.EnvUnset})
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq <= 0 {
+			return nil, fmt.Errorf("line %d: expected NAME=value, NAME+=value or -NAME", i+1)
+		}
+		name, value := line[:eq], line[eq+1:]
+		mode := config.EnvSet
+		if strings.HasSuffix(name, "+") {
+			name = strings.TrimSuffix(name, "+")
+			mode = config.EnvPrepend
+		}
+		name = strings.TrimSpace(name)
+		if !validEnvName(name) {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", i+1, name)
+		}
+		vars = append(vars, config.EnvVar{Name: name, Value: value, Mode: mode})
+	}
+
+	return vars, nil
+}
+
+// CheckFlatpakEnvironment rejects overrides that cannot be applied inside a
+// Flatpak sandbox: prepending needs the sandbox's own path list, which is
+// not known outside of it
+func CheckFlatpakEnvironment(vars []config.EnvVar) error {
+	for _, v := range vars {
+		if v.Mode == config.EnvPrepend {
+			return fmt.Errorf("%s+=: prepending is not supported for Flatpak slicers, set the whole value with %s=", v.Name, v.Name)
+		}
+	}
+	return nil
+}
+
+// FormatEnvironment formats environment overrides in the ParseEnvironment syntax
+func FormatEnvironment(vars []config.EnvVar) string {
+	lines := make([]string, 0, len(vars))
+	for _, v := range vars {
+		switch v.Mode {
+		case config.EnvUnset:
+			lines = append(lines, "-"+v.Name)
+		case config.EnvPrepend:
+			lines = append(lines, v.Name+"+="+v.Value)
+		default:
+			lines = append(lines, v.Name+"="+v.Value)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// buildEnvironment applies overrides to base, a list of NAME=value entries.
+// Values may reference other variables as ${NAME}.
+func buildEnvironment(base []string, vars []config.EnvVar) []string {
+	env := append([]string(nil), base...)
+
+	for _, v := range vars {
+		index := -1
+		for i, entry := range env {
+			if name, _, ok := strings.Cut(entry, "="); ok && sameEnvName(name, v.Name) {
+				index = i
+				break
+			}
+		}
+
+		value := expandEnvValue(v.Value, env)
+		switch v.Mode {
+		case config.EnvUnset:
+			if index >= 0 {
+				env = append(env[:index], env[index+1:]...)
+			}
+			continue
+		case config.EnvPrepend:
+			if index >= 0 {
+				if _, current, _ := strings.Cut(env[index], "="); current != "" {
+					value = value + string(os.PathListSeparator) + current
+				}
+			}
+		}
+
+		if index >= 0 {
+			env[index] = v.Name + "=" + value
+		} else {
+			env = append(env, v.Name+"="+value)
+		}
+	}
+
+	return env
+}
+
+// flatpakEnvOptions translates overrides into flatpak run options, since the
+// sandbox does not inherit the host environment. Prepend overrides are left
+// out, see CheckFlatpakEnvironment.
+func flatpakEnvOptions(vars []config.EnvVar) []string {
+	options := make([]string, 0, len(vars))
+	for _, v := range vars {
+		value := expandEnvValue(v.Value, os.Environ())
+		switch v.Mode {
+		case config.EnvUnset:
+			options = append(options, "--unset-env="+v.Name)
+		case config.EnvPrepend:
+			// The host's path list would replace the sandbox's
+			continue
+		default:
+			options = append(options, "--env="+v.Name+"="+value)
+		}
+	}
+	return options
+}
+
+// expandEnvValue replaces ${NAME} references with values from env
+func expandEnvValue(value string, env []string) string {
+	return envPlaceholderPattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := match[2 : len(match)-1]
+		for _, entry := range env {
+			if key, val, ok := strings.Cut(entry, "="); ok && sameEnvName(key, name) {
+				return val
+			}
+		}
+		return ""
+	})
+}
+
+func sameEnvName(a, b string) bool {
+	// Environment variable names are case-insensitive on Windows
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+func validEnvName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "= \t")
+}
@@ -0,0 +1,30 @@
+package slicer
+
+import (
+	"qslicerpicker/internal/config"
+	"reflect"
+	"testing"
+)
+
+func TestFlatpakEnvOptions(t *testing.T) {
+	t.Setenv("PATH", "/host/bin")
+	t.Setenv("QSP_THEME", "dark")
+
+	vars := []config.EnvVar{
+		{Name: "GDK_BACKEND", Value: "x11"},
+		{Name: "GTK_THEME", Value: "Adwaita:${QSP_THEME}"},
+		{Name: "PATH", Value: "/opt/tools", Mode: config.EnvPrepend},
+		{Name: "QT_SCALE_FACTOR", Mode: config.EnvUnset},
+	}
+	want := []string{"--env=GDK_BACKEND=x11", "--env=GTK_THEME=Adwaita:dark", "--unset-env=QT_SCALE_FACTOR"}
+	if got := flatpakEnvOptions(vars); !reflect.DeepEqual(got, want) {
+		t.Errorf("flatpakEnvOptions() = %q, want %q", got, want)
+	}
+
+	if err := CheckFlatpakEnvironment(vars); err == nil {
+		t.Error("CheckFlatpakEnvironment() accepted a prepend override")
+	}
+	if err := CheckFlatpakEnvironment(vars[:2]); err != nil {
+		t.Errorf("CheckFlatpakEnvironment() = %v", err)
+	}
+}
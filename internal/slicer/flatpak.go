@@ -0,0 +1,118 @@
+package slicer
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SourceFlatpak marks installations found in a Flatpak installation
+const SourceFlatpak = "flatpak"
+
+// flatpakInstallationDirs returns the user and system Flatpak installation
+// directories, including extra system installations from installations.d
+func flatpakInstallationDirs() []string {
+	dirs := make([]string, 0, 3)
+
+	if dir := os.Getenv("FLATPAK_USER_DIR"); dir != "" {
+		dirs = append(dirs, dir)
+	} else if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		dirs = append(dirs, filepath.Join(dataHome, "flatpak"))
+	} else if homeDir, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(homeDir, ".local", "share", "flatpak"))
+	}
+
+	if dir := os.Getenv("FLATPAK_SYSTEM_DIR"); dir != "" {
+		dirs = append(dirs, dir)
+	} else {
+		dirs = append(dirs, "/var/lib/flatpak")
+	}
+
+	// Extra system installations are declared as "Path=..." in ini files
+	confFiles, _ := filepath.Glob("/etc/flatpak/installations.d/*.conf")
+	for _, confFile := range confFiles {
+		file, err := os.Open(confFile)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "Path=") {
+				dirs = append(dirs, strings.TrimPrefix(line, "Path="))
+			}
+		}
+		file.Close()
+	}
+
+	return dirs
+}
+
+// FindFlatpak returns the deployed files directory of the Flatpak app with the
+// given ID, searching the user installation first
+func FindFlatpak(appID string) (string, bool) {
+	if appID == "" {
+		return "", false
+	}
+	for _, dir := range flatpakInstallationDirs() {
+		active := filepath.Join(dir, "app", appID, "current", "active")
+		if info, err := os.Stat(filepath.Join(active, "files")); err == nil && info.IsDir() {
+			return active, true
+		}
+	}
+	return "", false
+}
+
+// flatpakIDFromPath extracts the app ID from a Flatpak export wrapper
+// (…/exports/bin/<id>) or deploy directory (…/app/<id>/…)
+func flatpakIDFromPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] != "flatpak" {
+			continue
+		}
+		rest := parts[i+1:]
+		if len(rest) == 3 && rest[0] == "exports" && rest[1] == "bin" {
+			return rest[2]
+		}
+		if len(rest) >= 2 && rest[0] == "app" {
+			return rest[1]
+		}
+	}
+	return ""
+}
+
+// flatpakCommand builds the command line that runs a Flatpak app with the
+// given run options and argument template, forwarding files so the sandboxed
+// app can read them
+func flatpakCommand(appID string, options []string, args []string, filePaths []string) (string, []string) {
+	flatpak, err := exec.LookPath("flatpak")
+	if err != nil {
+		flatpak = "/usr/bin/flatpak"
+	}
+
+	cmdArgs := []string{"run"}
+	cmdArgs = append(cmdArgs, options...)
+	cmdArgs = append(cmdArgs, "--file-forwarding", appID)
+	cmdArgs = append(cmdArgs, expandTemplate(args, filePaths, forwardFiles)...)
+	if !HasFilePlaceholder(args) && len(filePaths) > 0 {
+		cmdArgs = append(cmdArgs, forwardFiles(filePaths)...)
+	}
+	return flatpak, cmdArgs
+}
+
+// forwardFiles encloses file arguments in the @@ markers of --file-forwarding
+func forwardFiles(filePaths []string) []string {
+	if len(filePaths) == 0 {
+		return nil
+	}
+	forwarded := make([]string, 0, len(filePaths)+2)
+	forwarded = append(forwarded, "@@")
+	forwarded = append(forwarded, filePaths...)
+	return append(forwarded, "@@")
+}
@@ -0,0 +1,76 @@
+package slicer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlatpakIDFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/var/lib/flatpak/exports/bin/com.prusa3d.PrusaSlicer", "com.prusa3d.PrusaSlicer"},
+		{"/home/user/.local/share/flatpak/exports/bin/io.github.softfever.OrcaSlicer", "io.github.softfever.OrcaSlicer"},
+		{"/var/lib/flatpak/app/com.bambulab.BambuStudio/current/active", "com.bambulab.BambuStudio"},
+		{"/var/lib/flatpak/app/com.ultimaker.cura/x86_64/stable/abc/files/bin/cura", "com.ultimaker.cura"},
+		{"/var/lib/flatpak/exports/bin/", ""},
+		{"/var/lib/flatpak/exports/share/applications/com.prusa3d.PrusaSlicer.desktop", ""},
+		{"/usr/bin/prusa-slicer", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := flatpakIDFromPath(tt.path); got != tt.want {
+			t.Errorf("flatpakIDFromPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFlatpakCommand(t *testing.T) {
+	// flatpak is not on the PATH, so its default location is used
+	t.Setenv("PATH", t.TempDir())
+
+	const appID = "com.prusa3d.PrusaSlicer"
+	files := []string{"/models/benchy.stl", "/models/my plate.3mf"}
+	tests := []struct {
+		name    string
+		options []string
+		args    []string
+		files   []string
+		want    []string
+	}{
+		{
+			"files appended", nil, []string{"--single-instance"}, files,
+			[]string{"run", "--file-forwarding", appID, "--single-instance", "@@", "/models/benchy.stl", "/models/my plate.3mf", "@@"},
+		},
+		{
+			"{files} in place", nil, []string{"--single-instance", "{files}", "--no-ensure-on-bed"}, files,
+			[]string{"run", "--file-forwarding", appID, "--single-instance", "@@", "/models/benchy.stl", "/models/my plate.3mf", "@@", "--no-ensure-on-bed"},
+		},
+		{
+			"{file} forwards the first file", nil, []string{"--load", "{file}"}, files,
+			[]string{"run", "--file-forwarding", appID, "--load", "@@", "/models/benchy.stl", "@@"},
+		},
+		{
+			"placeholder inside an argument", nil, []string{"--output={dir}/{stem}.gcode"}, files[:1],
+			[]string{"run", "--file-forwarding", appID, "--output=/models/benchy.gcode"},
+		},
+		{
+			"run options before the app", []string{"--env=GDK_BACKEND=x11", "--unset-env=QT_SCALE_FACTOR"}, nil, files,
+			[]string{"run", "--env=GDK_BACKEND=x11", "--unset-env=QT_SCALE_FACTOR", "--file-forwarding", appID, "@@", "/models/benchy.stl", "/models/my plate.3mf", "@@"},
+		},
+		{
+			"no files", nil, []string{"--single-instance"}, nil,
+			[]string{"run", "--file-forwarding", appID, "--single-instance"},
+		},
+	}
+	for _, tt := range tests {
+		name, args := flatpakCommand(appID, tt.options, tt.args, tt.files)
+		if name != "/usr/bin/flatpak" {
+			t.Errorf("%s: program = %q, want /usr/bin/flatpak", tt.name, name)
+		}
+		if !reflect.DeepEqual(args, tt.want) {
+			t.Errorf("%s: arguments = %q, want %q", tt.name, args, tt.want)
+		}
+	}
+}
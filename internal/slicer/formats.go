@@ -0,0 +1,64 @@
+package slicer
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultFormats lists the file extensions each slicer can open. Slicers
+// without an entry are assumed to open any file.
+var defaultFormats = map[string][]string{
+	"cura":        {"3mf", "stl", "obj", "ply", "x3d", "amf", "gcode", "ufp", "jpg", "jpeg", "png", "bmp"},
+	"prusaslicer": {"3mf", "stl", "obj", "amf", "step", "stp", "svg", "gcode", "bgcode"},
+	"superslicer": {"3mf", "stl", "obj", "amf", "step", "stp", "gcode"},
+	"orcaslicer":  {"3mf", "stl", "obj", "amf", "step", "stp", "svg", "gcode"},
+	"bambustudio": {"3mf", "stl", "obj", "amf", "step", "stp", "svg"},
+	"slic3r":      {"3mf", "stl", "obj", "amf"},
+	"ideamaker":   {"3mf", "stl", "obj", "ply", "idea", "gcode"},
+	"simplify3d":  {"3mf", "stl", "obj", "amf", "factory", "gcode"},
+	"kisslicer":   {"stl", "obj"},
+	"slic3rpe":    {"3mf", "stl", "obj", "amf", "prusa"},
+}
+
+// DefaultFormats returns the built-in extensions of a default slicer
+func DefaultFormats(id string) []string {
+	return defaultFormats[id]
+}
+
+// Supports reports whether the slicer can open the file. Extensions may span
+// several dots, e.g. "gcode.3mf".
+func (s Slicer) Supports(filePath string) bool {
+	if len(s.Formats) == 0 {
+		return true
+	}
+	name := strings.ToLower(filepath.Base(filePath))
+	for _, format := range s.Formats {
+		if strings.HasSuffix(name, "."+strings.ToLower(strings.TrimPrefix(format, "."))) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnsupportedExtensions returns the sorted extensions of the files the slicer
+// cannot open
+func (s Slicer) UnsupportedExtensions(filePaths []string) []string {
+	seen := make(map[string]bool)
+	var extensions []string
+	for _, filePath := range filePaths {
+		if s.Supports(filePath) {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(filePath))
+		if ext == "" {
+			ext = filepath.Base(filePath)
+		}
+		if !seen[ext] {
+			seen[ext] = true
+			extensions = append(extensions, ext)
+		}
+	}
+	sort.Strings(extensions)
+	return extensions
+}
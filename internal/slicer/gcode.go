@@ -0,0 +1,43 @@
+package slicer
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// defaultViewerArguments start slicers in their G-code viewer instead of the
+// model editor
+var defaultViewerArguments = map[string][]string{
+	"prusaslicer": {"--gcodeviewer"},
+	"superslicer": {"--gcodeviewer"},
+}
+
+// DefaultViewerArguments returns the built-in viewer arguments of a default
+// slicer
+func DefaultViewerArguments(id string) []string {
+	return defaultViewerArguments[id]
+}
+
+// IsGCode reports whether a file is sliced G-code rather than a model
+func IsGCode(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gcode", ".gco", ".g", ".bgcode":
+		return true
+	}
+	return false
+}
+
+// launchArguments returns the argument template for filePaths: the viewer
+// arguments if every file is G-code and the slicer has a viewer mode,
+// otherwise its regular arguments
+func (s Slicer) launchArguments(filePaths []string) []string {
+	if len(s.ViewerArguments) == 0 || len(filePaths) == 0 {
+		return s.Arguments
+	}
+	for _, filePath := range filePaths {
+		if !IsGCode(filePath) {
+			return s.Arguments
+		}
+	}
+	return s.ViewerArguments
+}
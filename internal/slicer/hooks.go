@@ -0,0 +1,223 @@
+package slicer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"qslicerpicker/internal/config"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables exposed to hooks
+const (
+	HookEnvSlicerID   = "QSLICERPICKER_SLICER_ID"
+	HookEnvSlicerName = "QSLICERPICKER_SLICER_NAME"
+	HookEnvFile       = "QSLICERPICKER_FILE"      // first file
+	HookEnvFiles      = "QSLICERPICKER_FILES"     // all files, one per line
+	HookEnvFileList   = "QSLICERPICKER_FILE_LIST" // file with one path per line; pre-launch hooks may rewrite it
+	HookEnvExitCode   = "QSLICERPICKER_EXIT_CODE" // slicer exit code (post-exit hooks only)
+)
+
+// preLaunchTimeout bounds how long a pre-launch hook may delay a launch
+const preLaunchTimeout = 2 * time.Minute
+
+// ErrVetoed is wrapped by errors of pre-launch hooks that stop a launch
+var ErrVetoed = errors.New("launch cancelled by pre-launch hook")
+
+// HookError describes a hook that failed or vetoed the launch
+type HookError struct {
+	Command  string
+	ExitCode int
+	Output   string // tail of the hook's output
+	Err      error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Command, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// pendingHooks tracks post-exit hooks waiting for their slicer to exit
+var pendingHooks sync.WaitGroup
+
+// Wait blocks until the post-exit hooks of all launched slicers have run
+func Wait() {
+	pendingHooks.Wait()
+}
+
+// preLaunchHooks returns the global pre-launch hooks followed by the slicer's
+func preLaunchHooks(slicer Slicer) []config.Arguments {
+	hooks := append([]config.Arguments(nil), config.GetConfig().Hooks.PreLaunch...)
+	return append(hooks, slicer.Hooks.PreLaunch...)
+}
+
+// postExitHooks returns the global post-exit hooks followed by the slicer's
+func postExitHooks(slicer Slicer) []config.Arguments {
+	hooks := append([]config.Arguments(nil), config.GetConfig().Hooks.PostExit...)
+	return append(hooks, slicer.Hooks.PostExit...)
+}
+
+// runPreLaunchHooks runs the pre-launch hooks in order and returns the file
+// list to open. A hook vetoes the launch by exiting non-zero or by removing
+// every file from the list, and rewrites it by editing the list file.
+func runPreLaunchHooks(slicer Slicer, filePaths []string) ([]string, error) {
+	for _, hook := range preLaunchHooks(slicer) {
+		listFile, err := writeFileList(filePaths)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), preLaunchTimeout)
+		output, exitCode, err := runHook(ctx, hook, slicer, filePaths, listFile, nil)
+		cancel()
+
+		rewritten, readErr := readFileList(listFile)
+		os.Remove(listFile)
+
+		if err != nil {
+			return nil, &HookError{
+				Command:  hookName(hook),
+				ExitCode: exitCode,
+				Output:   output,
+				Err:      fmt.Errorf("%w: %v", ErrVetoed, err),
+			}
+		}
+		if readErr == nil {
+			if len(rewritten) == 0 {
+				return nil, &HookError{Command: hookName(hook), Output: output, Err: fmt.Errorf("%w: no files left", ErrVetoed)}
+			}
+			filePaths = rewritten
+		}
+	}
+	return filePaths, nil
+}
+
+// runPostExitHooks runs the post-exit hooks once the process has exited
+func runPostExitHooks(slicer Slicer, p *process, filePaths []string) {
+	hooks := postExitHooks(slicer)
+	if len(hooks) == 0 {
+		return
+	}
+
+	pendingHooks.Add(1)
+	go func() {
+		defer pendingHooks.Done()
+
+		err := <-p.exited
+		p.exited <- err
+		exitCode := 0
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		} else if err != nil {
+			exitCode = -1
+		}
+
+		extra := []string{HookEnvExitCode + "=" + strconv.Itoa(exitCode)}
+		for _, hook := range hooks {
+			if _, _, err := runHook(context.Background(), hook, slicer, filePaths, "", extra); err != nil {
+				fmt.Fprintf(os.Stderr, "Post-exit hook %s failed: %v\n", hookName(hook), err)
+			}
+		}
+	}()
+}
+
+// runHook runs a single hook command and returns its output tail and exit code
+func runHook(ctx context.Context, hook config.Arguments, slicer Slicer, filePaths []string, listFile string, extraEnv []string) (string, int, error) {
+	args := expandTemplate(hook, filePaths, nil)
+	if len(args) == 0 {
+		return "", 0, nil
+	}
+
+	// Allow hook scripts like ~/bin/hook.sh
+	cmd := exec.CommandContext(ctx, expandPath(args[0]), args[1:]...)
+	cmd.Env = append(os.Environ(),
+		HookEnvSlicerID+"="+slicer.ID,
+		HookEnvSlicerName+"="+slicer.Name,
+		HookEnvFile+"="+strings.Join(firstFile(filePaths), ""),
+		HookEnvFiles+"="+strings.Join(filePaths, "\n"),
+	)
+	if listFile != "" {
+		cmd.Env = append(cmd.Env, HookEnvFileList+"="+listFile)
+	}
+	cmd.Env = append(cmd.Env, extraEnv...)
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	exitCode := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	} else if err != nil {
+		exitCode = -1
+	}
+
+	tail := output.Bytes()
+	if len(tail) > outputTailSize {
+		tail = tail[len(tail)-outputTailSize:]
+	}
+	return strings.TrimSpace(string(tail)), exitCode, err
+}
+
+func hookName(hook config.Arguments) string {
+	if len(hook) == 0 {
+		return ""
+	}
+	return hook[0]
+}
+
+// writeFileList writes the files to a temporary list file, one per line
+func writeFileList(filePaths []string) (string, error) {
+	file, err := os.CreateTemp("", "qslicerpicker-files-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(strings.Join(filePaths, "\n") + "\n"); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// readFileList reads a list file written by writeFileList, possibly edited
+func readFileList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// ErrorOutput returns the captured output of a failed slicer or hook, if any
+func ErrorOutput(err error) string {
+	var launchErr *LaunchError
+	if errors.As(err, &launchErr) {
+		return launchErr.Output
+	}
+	var hookErr *HookError
+	if errors.As(err, &hookErr) {
+		return hookErr.Output
+	}
+	return ""
+}
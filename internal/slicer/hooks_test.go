@@ -0,0 +1,151 @@
+package slicer
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"qslicerpicker/internal/config"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// withConfig changes the loaded configuration until the test ends
+func withConfig(t *testing.T, change func(cfg *config.Config)) {
+	t.Helper()
+	cfg := config.GetConfig()
+	saved := *cfg
+	change(cfg)
+	t.Cleanup(func() { *cfg = saved })
+}
+
+// writeScript writes an executable shell script and returns its path
+func writeScript(t *testing.T, name, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are shell scripts")
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPreLaunchHookVeto(t *testing.T) {
+	veto := writeScript(t, "veto.sh", "echo 'checking'\necho 'printer is busy' >&2\nexit 3\n")
+	marker := filepath.Join(t.TempDir(), "ran")
+	later := writeScript(t, "later.sh", "touch '"+marker+"'\n")
+	withConfig(t, func(cfg *config.Config) {
+		cfg.Hooks = config.Hooks{PreLaunch: []config.Arguments{{veto}}}
+	})
+
+	s := Slicer{ID: "prusaslicer", Name: "PrusaSlicer", Hooks: config.Hooks{PreLaunch: []config.Arguments{{later}}}}
+	_, err := runPreLaunchHooks(s, []string{"/models/benchy.stl"})
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) || !errors.Is(err, ErrVetoed) {
+		t.Fatalf("runPreLaunchHooks() = %v, want a veto", err)
+	}
+	if hookErr.Command != veto || hookErr.ExitCode != 3 || hookErr.Output != "checking\nprinter is busy" {
+		t.Errorf("hook error = %+v", hookErr)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("a hook ran after the veto")
+	}
+}
+
+func TestPreLaunchHookRewritesFiles(t *testing.T) {
+	// The global hook runs first and drops the STEP file, the slicer's hook
+	// adds a file
+	dropStep := writeScript(t, "drop-step.sh", `grep -v '\.step$' "$QSLICERPICKER_FILE_LIST" > "$QSLICERPICKER_FILE_LIST.new"
+mv "$QSLICERPICKER_FILE_LIST.new" "$QSLICERPICKER_FILE_LIST"
+`)
+	addPlate := writeScript(t, "add-plate.sh", `echo '/models/plate.3mf' >> "$QSLICERPICKER_FILE_LIST"`+"\n")
+	withConfig(t, func(cfg *config.Config) {
+		cfg.Hooks = config.Hooks{PreLaunch: []config.Arguments{{dropStep}}}
+	})
+
+	s := Slicer{ID: "prusaslicer", Name: "PrusaSlicer", Hooks: config.Hooks{PreLaunch: []config.Arguments{{addPlate}}}}
+	files, err := runPreLaunchHooks(s, []string{"/models/benchy.stl", "/models/bracket.step"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/models/benchy.stl", "/models/plate.3mf"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %q, want %q", files, want)
+	}
+}
+
+func TestPreLaunchHookEmptyListVetoes(t *testing.T) {
+	emptyList := writeScript(t, "clear.sh", `: > "$QSLICERPICKER_FILE_LIST"`+"\n")
+	withConfig(t, func(cfg *config.Config) { cfg.Hooks = config.Hooks{} })
+
+	s := Slicer{ID: "cura", Name: "Cura", Hooks: config.Hooks{PreLaunch: []config.Arguments{{emptyList}}}}
+	if _, err := runPreLaunchHooks(s, []string{"/models/benchy.stl"}); !errors.Is(err, ErrVetoed) {
+		t.Errorf("runPreLaunchHooks() = %v, want a veto", err)
+	}
+}
+
+func TestHookEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	dump := writeScript(t, "dump.sh", `for name in SLICER_ID SLICER_NAME FILE FILES FILE_LIST EXIT_CODE; do
+	eval "value=\${QSLICERPICKER_$name-unset}"
+	printf '%s' "$value" > "$1/$name"
+done
+`)
+	withConfig(t, func(cfg *config.Config) { cfg.Hooks = config.Hooks{} })
+
+	s := Slicer{ID: "orcaslicer", Name: "Orca Slicer", Hooks: config.Hooks{PreLaunch: []config.Arguments{{dump, dir}}}}
+	files := []string{"/models/benchy.stl", "/models/my plate.3mf"}
+	if _, err := runPreLaunchHooks(s, files); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"SLICER_ID":   "orcaslicer",
+		"SLICER_NAME": "Orca Slicer",
+		"FILE":        "/models/benchy.stl",
+		"FILES":       "/models/benchy.stl\n/models/my plate.3mf",
+		"EXIT_CODE":   "unset",
+	}
+	for name, value := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != value {
+			t.Errorf("QSLICERPICKER_%s = %q, want %q", name, data, value)
+		}
+	}
+	listFile, _ := os.ReadFile(filepath.Join(dir, "FILE_LIST"))
+	if len(listFile) == 0 {
+		t.Error("QSLICERPICKER_FILE_LIST not set")
+	} else if _, err := os.Stat(string(listFile)); err == nil {
+		t.Error("the list file was not removed")
+	}
+}
+
+func TestPostExitHookExitCode(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	out := filepath.Join(t.TempDir(), "exit-code")
+	hook := writeScript(t, "post.sh", `printf '%s %s' "$QSLICERPICKER_EXIT_CODE" "$QSLICERPICKER_FILE" > '`+out+"'\n")
+	withConfig(t, func(cfg *config.Config) {
+		cfg.Hooks = config.Hooks{PostExit: []config.Arguments{{hook}}}
+	})
+
+	p, err := startProcess("slicer", exec.Command("sh", "-c", "exit 4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runPostExitHooks(Slicer{ID: "prusaslicer", Name: "PrusaSlicer"}, p, []string{"/models/benchy.stl"})
+	Wait()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "4 /models/benchy.stl" {
+		t.Errorf("post-exit hook saw %q, want exit code 4 for benchy.stl", got)
+	}
+}
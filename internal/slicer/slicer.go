@@ -7,18 +7,32 @@ import (
 	"path/filepath"
 	"qslicerpicker/internal/config"
 	"runtime"
+	"strings"
+	"time"
 )
 
 type Slicer struct {
-	ID          string
-	Name        string
-	DefaultPath string
-	Path        string
-	Enabled     bool
-	Order       int
-	Arguments   []string
-	WorkingDir  string
-	IsCustom    bool
+	ID              string
+	Name            string
+	DefaultPath     string
+	Path            string
+	Enabled         bool
+	Order           int
+	Arguments       []string
+	WorkingDir      string
+	ViewerArguments []string // replace Arguments when every file is G-code
+	Formats         []string // extensions the slicer opens; empty means any
+	Icon            string   // image file chosen by the user; empty finds one
+	Version         string   // set for installations listed separately
+	IsCustom        bool
+	FlatpakID       string // when set, the slicer is started with "flatpak run"
+	SingleFile      bool   // the slicer opens one file per invocation
+	Environment     []config.EnvVar
+	Wrappers        []config.Arguments // commands the slicer is run under, outermost first
+	Hooks           config.Hooks
+
+	// Installations lists every installation found by Discover
+	Installations []Installation
 }
 
 var defaultSlicers = []struct {
@@ -86,13 +100,15 @@ func GetDefaultSlicers() []Slicer {
 	for i, ds := range defaultSlicers {
 		defaultPath := ds.DefaultPath[platform]
 		slicers = append(slicers, Slicer{
-			ID:          ds.ID,
-			Name:        ds.Name,
-			DefaultPath: defaultPath,
-			Path:        defaultPath,
-			Enabled:     true,
-			Order:       i * 10, // Default order with spacing for reordering
-			IsCustom:    false,
+			ID:              ds.ID,
+			Name:            ds.Name,
+			DefaultPath:     defaultPath,
+			Path:            defaultPath,
+			Enabled:         true,
+			Order:           i * 10, // Default order with spacing for reordering
+			IsCustom:        false,
+			ViewerArguments: defaultViewerArguments[ds.ID],
+			Formats:         defaultFormats[ds.ID],
 		})
 	}
 
@@ -127,29 +143,70 @@ func LoadSlicers() []Slicer {
 	slicers := make([]Slicer, 0)
 	for _, ds := range defaultSlicers {
 		slicer := ds
-		if sc, ok := configMap[ds.ID]; ok {
+		slicer.Installations = Discover(ds.ID)
+		if len(slicer.Installations) > 0 {
+			slicer.Path = slicer.Installations[0].Path
+			slicer.FlatpakID = slicer.Installations[0].FlatpakID
+		}
+		sc, ok := configMap[ds.ID]
+		if ok {
 			slicer.Enabled = sc.Enabled
 			slicer.Order = sc.Order
 			if sc.CustomPath != "" {
 				slicer.Path = sc.CustomPath
+				slicer.FlatpakID = flatpakIDFromPath(sc.CustomPath)
+			}
+			if sc.FlatpakID != "" {
+				slicer.FlatpakID = sc.FlatpakID
 			}
 			slicer.Arguments = sc.Arguments
+			// Empty viewer arguments turn off the default
+			if sc.ViewerArguments != nil {
+				slicer.ViewerArguments = sc.ViewerArguments
+			}
+			// Empty formats let the slicer open any file
+			if sc.Formats != nil {
+				slicer.Formats = sc.Formats
+			}
+			slicer.Icon = sc.Icon
 			slicer.WorkingDir = sc.WorkingDir
+			slicer.SingleFile = sc.SingleFile
+			slicer.Environment = sc.Environment
+			slicer.Wrappers = sc.Wrappers
+			slicer.Hooks = sc.Hooks
+		}
+
+		// A path chosen in the settings pins the slicer to one installation
+		if cfg.SeparateVersions && sc.CustomPath == "" && sc.FlatpakID == "" && len(slicer.Installations) > 1 {
+			slicers = append(slicers, separateVersions(slicer, configMap)...)
+			continue
 		}
 		slicers = append(slicers, slicer)
 	}
 
 	// Add custom slicers
 	for i, cs := range cfg.CustomSlicers {
+		flatpakID := cs.FlatpakID
+		if flatpakID == "" {
+			flatpakID = flatpakIDFromPath(cs.Path)
+		}
 		slicers = append(slicers, Slicer{
-			ID:         fmt.Sprintf("custom_%d", i),
-			Name:       cs.Name,
-			Path:       cs.Path,
-			Enabled:    cs.Enabled,
-			Order:      cs.Order,
-			Arguments:  cs.Arguments,
-			WorkingDir: cs.WorkingDir,
-			IsCustom:   true,
+			ID:              fmt.Sprintf("custom_%d", i),
+			Name:            cs.Name,
+			Path:            cs.Path,
+			Enabled:         cs.Enabled,
+			Order:           cs.Order,
+			Arguments:       cs.Arguments,
+			WorkingDir:      cs.WorkingDir,
+			IsCustom:        true,
+			FlatpakID:       flatpakID,
+			SingleFile:      cs.SingleFile,
+			Environment:     cs.Environment,
+			Wrappers:        cs.Wrappers,
+			Hooks:           cs.Hooks,
+			ViewerArguments: cs.ViewerArguments,
+			Formats:         cs.Formats,
+			Icon:            cs.Icon,
 		})
 	}
 
@@ -165,17 +222,98 @@ func LoadSlicers() []Slicer {
 	return slicers
 }
 
+// separateVersions returns an entry for each installed version of a default
+// slicer. The first installation keeps the slicer's ID, the others get
+// "<id>@<version>" and take their settings from it unless they have their
+// own. Installations whose version is unknown or already listed are left out.
+func separateVersions(base Slicer, configMap map[string]config.SlicerConfig) []Slicer {
+	DetectVersions(base.ID, base.Installations)
+
+	first := base.Installations[0]
+	base.Version = first.Version
+	if base.Version != "" {
+		base.Name += " " + base.Version
+	}
+	slicers := []Slicer{base}
+
+	seen := map[string]bool{first.Version: true}
+	for i, inst := range base.Installations[1:] {
+		if inst.Version == "" || seen[inst.Version] {
+			continue
+		}
+		seen[inst.Version] = true
+
+		slicer := base
+		slicer.ID = base.ID + "@" + inst.Version
+		slicer.Name = strings.TrimSuffix(base.Name, " "+base.Version) + " " + inst.Version
+		slicer.Version = inst.Version
+		slicer.Path = inst.Path
+		slicer.FlatpakID = inst.FlatpakID
+		slicer.Order = base.Order + 1 + i // right after the first installation
+		if sc, ok := configMap[slicer.ID]; ok {
+			applyVersionConfig(&slicer, sc)
+		}
+		slicers = append(slicers, slicer)
+	}
+	return slicers
+}
+
+// applyVersionConfig applies the settings of a separately listed version.
+// Enabled and the order are its own, other settings only when they are set.
+func applyVersionConfig(slicer *Slicer, sc config.SlicerConfig) {
+	slicer.Enabled = sc.Enabled
+	slicer.Order = sc.Order
+	if sc.CustomPath != "" {
+		slicer.Path = sc.CustomPath
+		slicer.FlatpakID = flatpakIDFromPath(sc.CustomPath)
+	}
+	if sc.FlatpakID != "" {
+		slicer.FlatpakID = sc.FlatpakID
+	}
+	if len(sc.Arguments) > 0 {
+		slicer.Arguments = sc.Arguments
+	}
+	if sc.ViewerArguments != nil {
+		slicer.ViewerArguments = sc.ViewerArguments
+	}
+	if sc.Formats != nil {
+		slicer.Formats = sc.Formats
+	}
+	if sc.Icon != "" {
+		slicer.Icon = sc.Icon
+	}
+	if sc.WorkingDir != "" {
+		slicer.WorkingDir = sc.WorkingDir
+	}
+	if sc.SingleFile {
+		slicer.SingleFile = true
+	}
+	if len(sc.Environment) > 0 {
+		slicer.Environment = sc.Environment
+	}
+	if len(sc.Wrappers) > 0 {
+		slicer.Wrappers = sc.Wrappers
+	}
+	if len(sc.Hooks.PreLaunch) > 0 || len(sc.Hooks.PostExit) > 0 {
+		slicer.Hooks = sc.Hooks
+	}
+}
+
+// BaseID returns the ID of the default slicer a separately listed version
+// belongs to, or the ID itself
+func (s Slicer) BaseID() string {
+	id, _, _ := strings.Cut(s.ID, "@")
+	return id
+}
+
 // GetEnabledSlicers returns only enabled slicers, sorted by order
 func GetEnabledSlicers() []Slicer {
 	allSlicers := LoadSlicers()
 	enabled := make([]Slicer, 0)
 
 	for _, s := range allSlicers {
-		if s.Enabled && s.Path != "" {
-			// Check if path exists
-			if _, err := os.Stat(s.Path); err == nil {
-				enabled = append(enabled, s)
-			}
+		if s.Enabled && s.IsInstalled() {
+			enabled = append(enabled, s)
 		}
 	}
 
@@ -191,45 +329,139 @@ func GetEnabledSlicers() []Slicer {
 	return enabled
 }
 
-// LaunchSlicer launches a slicer with the given file
-func LaunchSlicer(slicer Slicer, filePath string) error {
-	var cmd *exec.Cmd
+// IsInstalled reports whether the slicer's executable or Flatpak app exists
+func (s Slicer) IsInstalled() bool {
+	if s.FlatpakID != "" {
+		_, ok := FindFlatpak(s.FlatpakID)
+		return ok
+	}
+	if s.Path == "" {
+		return false
+	}
+	_, err := os.Stat(s.Path)
+	return err == nil
+}
+
+// LaunchSlicer launches a slicer with the given files, once for all files or
+// once per file for slicers that only accept a single file or whose argument
+// template only passes {file}. Pre-launch hooks
+// run first and may veto the launch (*HookError). The slicer is then watched
+// for the grace period; if it exits with an error in that time a
+// *LaunchError with its exit status and output is returned. Post-exit hooks
+// run when the slicer exits, see Wait.
+func LaunchSlicer(slicer Slicer, filePaths []string) error {
+	filePaths, err := runPreLaunchHooks(slicer, filePaths)
+	if err != nil {
+		return err
+	}
 
-	if runtime.GOOS == "darwin" {
-		// Check if path is a .app bundle
-		if filepath.Ext(slicer.Path) == ".app" || (filepath.Dir(slicer.Path) != "" && filepath.Ext(filepath.Dir(slicer.Path)) == ".app") {
-			// macOS .app bundle - find the .app path
-			appPath := slicer.Path
-			if filepath.Ext(slicer.Path) != ".app" {
-				// Path is inside .app bundle, find the .app
-				dir := filepath.Dir(slicer.Path)
-				for dir != "/" && dir != "." {
-					if filepath.Ext(dir) == ".app" {
-						appPath = dir
-						break
-					}
-					dir = filepath.Dir(dir)
-				}
-			}
-			// Use open command for .app bundles
-			args := []string{"-a", appPath, filePath}
-			cmd = exec.Command("open", args...)
-		} else {
-			// Regular executable
-			args := append(slicer.Arguments, filePath)
-			cmd = exec.Command(slicer.Path, args...)
+	groups := [][]string{filePaths}
+	singleFile := slicer.SingleFile || takesOneFile(slicer.launchArguments(filePaths))
+	if singleFile && len(filePaths) > 1 {
+		groups = make([][]string, 0, len(filePaths))
+		for _, filePath := range filePaths {
+			groups = append(groups, []string{filePath})
+		}
+	}
+
+	processes := make([]*process, 0, len(groups))
+	for _, files := range groups {
+		p, err := startSlicer(slicer, files)
+		if err != nil {
+			return err
 		}
-	} else {
-		// Windows and Linux
-		args := append(slicer.Arguments, filePath)
-		cmd = exec.Command(slicer.Path, args...)
+		runPostExitHooks(slicer, p, files)
+		processes = append(processes, p)
 	}
 
+	deadline := time.Now().Add(GracePeriod())
+	for _, p := range processes {
+		if err := p.supervise(deadline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GracePeriod returns how long launched slicers are watched for early failures
+func GracePeriod() time.Duration {
+	if seconds := config.GetConfig().LaunchGraceSeconds; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return DefaultGracePeriod
+}
+
+// startSlicer starts a single slicer process for filePaths
+func startSlicer(slicer Slicer, filePaths []string) (*process, error) {
+	name, args := buildCommand(slicer, filePaths)
+	cmd := exec.Command(name, args...)
+
 	if slicer.WorkingDir != "" {
 		cmd.Dir = slicer.WorkingDir
 	}
+	if len(slicer.Environment) > 0 && slicer.FlatpakID == "" {
+		cmd.Env = buildEnvironment(os.Environ(), slicer.Environment)
+	}
+
+	return startProcess(slicer.Name, cmd)
+}
+
+// buildCommand returns the program and arguments that open filePaths with
+// the slicer, run under the global and the slicer's wrapper commands
+func buildCommand(slicer Slicer, filePaths []string) (string, []string) {
+	name, args := slicerCommand(slicer, filePaths)
+
+	wrappers := make([]config.Arguments, 0, len(config.GetConfig().Wrappers)+len(slicer.Wrappers))
+	wrappers = append(wrappers, config.GetConfig().Wrappers...)
+	wrappers = append(wrappers, slicer.Wrappers...)
+	return wrapCommand(name, args, wrappers, filePaths)
+}
+
+// slicerCommand returns the program and arguments that open filePaths with
+// the slicer, expanding placeholders in its argument template (or its viewer
+// template for G-code)
+func slicerCommand(slicer Slicer, filePaths []string) (string, []string) {
+	arguments := slicer.launchArguments(filePaths)
 
-	return cmd.Start()
+	if slicer.FlatpakID != "" {
+		// Flatpak apps have no host binary, run them through flatpak
+		options := flatpakEnvOptions(slicer.Environment)
+		return flatpakCommand(slicer.FlatpakID, options, arguments, filePaths)
+	}
+
+	if runtime.GOOS == "darwin" {
+		if appPath, ok := appBundlePath(slicer.Path); ok {
+			// Use open command for .app bundles; files go to open itself
+			// unless the template places them explicitly
+			args := []string{"-a", appPath}
+			if HasFilePlaceholder(arguments) {
+				args = append(args, "--args")
+				args = append(args, ExpandArguments(arguments, filePaths)...)
+			} else {
+				args = append(args, filePaths...)
+				if len(arguments) > 0 {
+					args = append(args, "--args")
+					args = append(args, ExpandArguments(arguments, nil)...)
+				}
+			}
+			return "open", args
+		}
+	}
+
+	// Regular executable
+	return slicer.Path, ExpandArguments(arguments, filePaths)
+}
+
+// appBundlePath returns the enclosing .app bundle of a macOS slicer path
+func appBundlePath(path string) (string, bool) {
+	dir := path
+	for dir != "/" && dir != "." && dir != "" {
+		if filepath.Ext(dir) == ".app" {
+			return dir, true
+		}
+		dir = filepath.Dir(dir)
+	}
+	return "", false
 }
 
 // FindSlicerByID finds a slicer by its ID
@@ -0,0 +1,160 @@
+package slicer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultGracePeriod is how long a launched slicer is watched for an
+	// early exit before it is left running on its own
+	DefaultGracePeriod = 3 * time.Second
+
+	outputTailSize = 4096
+	logMaxAge      = 7 * 24 * time.Hour
+)
+
+// LaunchError describes a slicer that failed to start or exited with an
+// error during the grace period
+type LaunchError struct {
+	Slicer   string
+	ExitCode int    // -1 if the process was killed by a signal
+	Output   string // tail of the slicer's output
+	Err      error
+}
+
+func (e *LaunchError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Slicer, e.Err)
+}
+
+func (e *LaunchError) Unwrap() error {
+	return e.Err
+}
+
+// process is a started slicer that is being supervised
+type process struct {
+	name   string
+	cmd    *exec.Cmd
+	log    *os.File
+	exited chan error
+}
+
+// startProcess starts cmd detached from the picker, with its output going to
+// a log file so that it can keep running after the picker exits
+func startProcess(name string, cmd *exec.Cmd) (*process, error) {
+	log, err := createLogFile(name)
+	if err == nil {
+		cmd.Stdout = log
+		cmd.Stderr = log
+	}
+	detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		if log != nil {
+			log.Close()
+			os.Remove(log.Name())
+		}
+		return nil, &LaunchError{Slicer: name, ExitCode: -1, Err: err}
+	}
+
+	p := &process{name: name, cmd: cmd, log: log, exited: make(chan error, 1)}
+	go func() {
+		p.exited <- cmd.Wait()
+	}()
+	return p, nil
+}
+
+// supervise waits until the deadline for the process to fail. A process that
+// is still running at the deadline, or exited successfully, is considered
+// launched.
+func (p *process) supervise(deadline time.Time) error {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case err := <-p.exited:
+		// Put the result back for anyone waiting on the exit later
+		p.exited <- err
+		output := p.readOutput()
+		if err == nil {
+			return nil
+		}
+		exitCode := -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		return &LaunchError{Slicer: p.name, ExitCode: exitCode, Output: output, Err: err}
+	case <-timer.C:
+		if p.log != nil {
+			p.log.Close()
+		}
+		return nil
+	}
+}
+
+// readOutput returns the tail of the log of an exited process and removes it
+func (p *process) readOutput() string {
+	if p.log == nil {
+		return ""
+	}
+	defer os.Remove(p.log.Name())
+	defer p.log.Close()
+
+	info, err := p.log.Stat()
+	if err != nil {
+		return ""
+	}
+	offset := info.Size() - outputTailSize
+	if offset < 0 {
+		offset = 0
+	}
+	data := make([]byte, info.Size()-offset)
+	if _, err := p.log.ReadAt(data, offset); err != nil && err != io.EOF {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// logDir returns the directory holding the output logs of launched slicers
+func logDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cacheDir, "qslicerpicker", "logs")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// createLogFile creates a fresh log file for a launch, pruning old logs
+func createLogFile(name string) (*os.File, error) {
+	dir, err := logDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > logMaxAge {
+				os.Remove(filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	prefix := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' || r == ':' {
+			return '_'
+		}
+		return r
+	}, name)
+	return os.CreateTemp(dir, prefix+"-*.log")
+}
@@ -0,0 +1,84 @@
+package slicer
+
+import (
+	"errors"
+	"os/exec"
+	"qslicerpicker/internal/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLaunchErrorWithinGracePeriod(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	// More output than the tail that is kept
+	script := writeScript(t, "prusa-slicer", `i=0
+while [ $i -lt 500 ]; do echo "loading profile $i"; i=$((i+1)); done
+echo "error: cannot open display" >&2
+exit 3
+`)
+	withConfig(t, func(cfg *config.Config) {
+		cfg.LaunchGraceSeconds = 10
+		cfg.Hooks = config.Hooks{}
+		cfg.Wrappers = nil
+	})
+
+	start := time.Now()
+	err := LaunchSlicer(Slicer{ID: "prusaslicer", Name: "PrusaSlicer", Path: script}, []string{"/models/benchy.stl"})
+	var launchErr *LaunchError
+	if !errors.As(err, &launchErr) {
+		t.Fatalf("LaunchSlicer() = %v, want a LaunchError", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the failure was reported after %v instead of when the slicer exited", elapsed)
+	}
+	if launchErr.Slicer != "PrusaSlicer" || launchErr.ExitCode != 3 {
+		t.Errorf("LaunchError = %q exit code %d, want PrusaSlicer exit code 3", launchErr.Slicer, launchErr.ExitCode)
+	}
+	if !strings.HasSuffix(launchErr.Output, "loading profile 499\nerror: cannot open display") {
+		t.Errorf("output does not end with the slicer's last lines: %q", launchErr.Output)
+	}
+	if len(launchErr.Output) > outputTailSize || strings.Contains(launchErr.Output, "loading profile 0\n") {
+		t.Errorf("output is %d bytes, want only the last %d", len(launchErr.Output), outputTailSize)
+	}
+	if ErrorOutput(err) != launchErr.Output {
+		t.Error("ErrorOutput() does not return the slicer's output")
+	}
+}
+
+func TestLongRunningSlicerIsLeftAlone(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	script := writeScript(t, "prusa-slicer", "echo started\nexec sleep 30\n")
+
+	p, err := startProcess("PrusaSlicer", exec.Command(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		p.cmd.Process.Kill()
+		<-p.exited
+	})
+
+	if err := p.supervise(time.Now().Add(300 * time.Millisecond)); err != nil {
+		t.Fatalf("supervise() = %v, want nil for a running slicer", err)
+	}
+	select {
+	case err := <-p.exited:
+		t.Errorf("the slicer exited (%v) instead of being left running", err)
+		p.exited <- err
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSuccessfulExitWithinGracePeriod(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	script := writeScript(t, "cura", "echo 'handed the file to the running instance'\n")
+
+	p, err := startProcess("Cura", exec.Command(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.supervise(time.Now().Add(10 * time.Second)); err != nil {
+		t.Errorf("supervise() = %v, want nil for a successful exit", err)
+	}
+}
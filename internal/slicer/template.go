@@ -0,0 +1,126 @@
+package slicer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Argument placeholders:
+//
+//	{file}      the first file
+//	{files}     all files; as a whole argument it expands to one argument per file
+//	{dir}       directory of the first file
+//	{basename}  file name of the first file (model.stl)
+//	{stem}      file name without extension (model)
+//	{ext}       extension without the dot (stl)
+//	${NAME}     value of the environment variable NAME
+var (
+	filePlaceholderPattern = regexp.MustCompile(`\{(file|files|dir|basename|stem|ext)\}`)
+	envPlaceholderPattern  = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	placeholderPattern     = regexp.MustCompile(envPlaceholderPattern.String() + `|` + filePlaceholderPattern.String())
+)
+
+// HasFilePlaceholder reports whether any argument references the opened files
+func HasFilePlaceholder(args []string) bool {
+	for _, arg := range args {
+		if filePlaceholderPattern.MatchString(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// takesOneFile reports whether the template passes only the first file: it
+// uses {file} but not {files}
+func takesOneFile(args []string) bool {
+	one, all := false, false
+	for _, arg := range args {
+		one = one || strings.Contains(arg, "{file}")
+		all = all || strings.Contains(arg, "{files}")
+	}
+	return one && !all
+}
+
+// ExpandArguments substitutes placeholders in args for the given files. If no
+// argument contains a file placeholder, the files are appended to the result.
+func ExpandArguments(args []string, filePaths []string) []string {
+	result := expandTemplate(args, filePaths, nil)
+	if !HasFilePlaceholder(args) {
+		result = append(result, filePaths...)
+	}
+	return result
+}
+
+// expandTemplate substitutes placeholders in args. When wrap is set, arguments
+// consisting solely of {file} or {files} are passed through it so that callers
+// can decorate file arguments (e.g. Flatpak's @@ forwarding markers).
+func expandTemplate(args []string, filePaths []string, wrap func([]string) []string) []string {
+	result := make([]string, 0, len(args)+len(filePaths))
+
+	for _, arg := range args {
+		switch arg {
+		case "{files}":
+			files := append([]string(nil), filePaths...)
+			if wrap != nil {
+				files = wrap(files)
+			}
+			result = append(result, files...)
+			continue
+		case "{file}":
+			files := firstFile(filePaths)
+			if wrap != nil {
+				files = wrap(files)
+			}
+			result = append(result, files...)
+			continue
+		}
+
+		// One pass over the template, so that file paths containing ${...}
+		// are not expanded again
+		expanded := placeholderPattern.ReplaceAllStringFunc(arg, func(match string) string {
+			if strings.HasPrefix(match, "$") {
+				return os.Getenv(match[2 : len(match)-1])
+			}
+			return placeholderValue(match[1:len(match)-1], filePaths)
+		})
+		result = append(result, expanded)
+	}
+
+	return result
+}
+
+func placeholderValue(name string, filePaths []string) string {
+	if name == "files" {
+		return strings.Join(filePaths, " ")
+	}
+	if len(filePaths) == 0 {
+		return ""
+	}
+
+	file := filePaths[0]
+	base := filepath.Base(file)
+	ext := filepath.Ext(base)
+
+	switch name {
+	case "file":
+		return file
+	case "dir":
+		return filepath.Dir(file)
+	case "basename":
+		return base
+	case "stem":
+		return strings.TrimSuffix(base, ext)
+	case "ext":
+		return strings.TrimPrefix(ext, ".")
+	}
+	return ""
+}
+
+func firstFile(filePaths []string) []string {
+	if len(filePaths) == 0 {
+		return nil
+	}
+	return filePaths[:1]
+}
@@ -0,0 +1,52 @@
+package slicer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandArguments(t *testing.T) {
+	t.Setenv("QSP_PROFILE", "/profiles")
+
+	tests := []struct {
+		name  string
+		args  []string
+		files []string
+		want  []string
+	}{
+		{"appends files", []string{"--single-instance"}, []string{"/a.stl", "/b.stl"}, []string{"--single-instance", "/a.stl", "/b.stl"}},
+		{"files as arguments", []string{"--load", "{files}"}, []string{"/a.stl", "/b.stl"}, []string{"--load", "/a.stl", "/b.stl"}},
+		{"first file", []string{"{file}"}, []string{"/a.stl", "/b.stl"}, []string{"/a.stl"}},
+		{"parts", []string{"--out={dir}/{stem}.gcode", "{basename}", "{ext}"}, []string{"/m/part.stl"}, []string{"--out=/m/part.gcode", "part.stl", "stl"}},
+		{"environment", []string{"--datadir", "${QSP_PROFILE}/pla"}, []string{"/a.stl"}, []string{"--datadir", "/profiles/pla", "/a.stl"}},
+		{"paths are not expanded", []string{"--input={file}"}, []string{"/tmp/${QSP_PROFILE}.stl"}, []string{"--input=/tmp/${QSP_PROFILE}.stl"}},
+		{"placeholder in path", []string{"{file}", "{ext}"}, []string{"/tmp/{ext}/a.stl"}, []string{"/tmp/{ext}/a.stl", "stl"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandArguments(tt.args, tt.files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandArguments(%q, %q) = %q, want %q", tt.args, tt.files, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTakesOneFile(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"--single-instance"}, false},
+		{[]string{"{file}"}, true},
+		{[]string{"--input={file}", "-o", "{dir}/{stem}.gcode"}, true},
+		{[]string{"{files}"}, false},
+		{[]string{"--first={file}", "{files}"}, false},
+		{[]string{"--out={dir}"}, false},
+	}
+	for _, tt := range tests {
+		if got := takesOneFile(tt.args); got != tt.want {
+			t.Errorf("takesOneFile(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
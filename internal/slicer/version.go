@@ -0,0 +1,336 @@
+package slicer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"qslicerpicker/internal/desktopentry"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// versionTimeout limits how long a slicer may take to print its version
+const versionTimeout = 5 * time.Second
+
+// versionArguments are the arguments that make a slicer print its version and
+// exit. Other slicers would open their window, so they are never run.
+var versionArguments = map[string][]string{
+	"prusaslicer": {"--version"},
+	"superslicer": {"--version"},
+	"orcaslicer":  {"--version"},
+	"bambustudio": {"--version"},
+	"slic3r":      {"--version"},
+	"slic3rpe":    {"--version"},
+}
+
+var (
+	versionPattern  = regexp.MustCompile(`\d+\.\d+(?:\.\d+)*(?:[-.]?(?:alpha|beta|rc)\d*)?`)
+	plistPattern    = regexp.MustCompile(`<key>CFBundleShortVersionString</key>\s*<string>([^<]+)</string>`)
+	metainfoPattern = regexp.MustCompile(`<release\b[^>]*\sversion="([^"]+)"`)
+)
+
+// DetectVersions sets the version of every installation of the slicer with
+// the given ID that can be found without running it. Versions printed by
+// versionArguments are only taken from the cache that RefreshVersions fills.
+func DetectVersions(id string, installations []Installation) {
+	for i := range installations {
+		if installations[i].Version == "" {
+			installations[i].Version = detectVersion(id, installations[i])
+		}
+	}
+}
+
+// detectVersion tries, in order: Flatpak metadata, the Info.plist of a
+// macOS app bundle, the AppImage file name, the X-AppImage-Version of the
+// AppImage's .desktop entry and the cached output of --version
+func detectVersion(id string, inst Installation) string {
+	if inst.Source == SourceFlatpak {
+		return flatpakVersion(inst.Path, inst.FlatpakID)
+	}
+	if bundle, ok := appBundlePath(inst.Path); ok {
+		return infoPlistVersion(bundle)
+	}
+	if strings.HasSuffix(strings.ToLower(inst.Path), ".appimage") {
+		if version := versionPattern.FindString(filepath.Base(inst.Path)); version != "" {
+			return version
+		}
+		return appImageEntryVersion(inst.Path)
+	}
+	if _, ok := versionCommand(id, inst); ok {
+		version, _ := cachedCommandVersion(inst.Path)
+		return version
+	}
+	return ""
+}
+
+// versionCommand returns the versionArguments of an installation whose
+// version is not in its metadata or file name
+func versionCommand(id string, inst Installation) ([]string, bool) {
+	if inst.Source == SourceFlatpak || inst.FlatpakID != "" || strings.HasSuffix(strings.ToLower(inst.Path), ".appimage") {
+		return nil, false
+	}
+	if _, ok := appBundlePath(inst.Path); ok {
+		return nil, false
+	}
+	args, ok := versionArguments[id]
+	return args, ok
+}
+
+// RefreshVersions runs the installed slicers whose version is only printed
+// by versionArguments and not cached yet. This takes up to versionTimeout
+// per slicer, so it runs in the background and LoadSlicers only reads the
+// cache. It reports whether a new version was found.
+func RefreshVersions() bool {
+	found := false
+	for id := range versionArguments {
+		for _, inst := range Discover(id) {
+			args, ok := versionCommand(id, inst)
+			if !ok {
+				continue
+			}
+			if _, cached := cachedCommandVersion(inst.Path); cached {
+				continue
+			}
+			if commandVersion(inst.Path, args) != "" {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// flatpakVersion reads the newest release from the app's AppStream metadata
+func flatpakVersion(deployDir, appID string) string {
+	for _, name := range []string{appID + ".metainfo.xml", appID + ".appdata.xml"} {
+		data, err := os.ReadFile(filepath.Join(deployDir, "files", "share", "metainfo", name))
+		if err != nil {
+			continue
+		}
+		// Releases are listed newest first
+		if match := metainfoPattern.FindSubmatch(data); match != nil {
+			return string(match[1])
+		}
+	}
+	return ""
+}
+
+// infoPlistVersion reads CFBundleShortVersionString from an XML Info.plist
+func infoPlistVersion(bundle string) string {
+	data, err := os.ReadFile(filepath.Join(bundle, "Contents", "Info.plist"))
+	if err != nil {
+		return ""
+	}
+	if match := plistPattern.FindSubmatch(data); match != nil {
+		return strings.TrimSpace(string(match[1]))
+	}
+	return ""
+}
+
+var (
+	appImageEntriesOnce sync.Once
+	appImageEntries     []*desktopentry.Entry
+)
+
+// appImageEntryVersion returns the X-AppImage-Version of the .desktop entry
+// that starts the AppImage. Integration tools often rename AppImages, which
+// drops the version from the file name.
+func appImageEntryVersion(appImage string) string {
+	appImageEntriesOnce.Do(func() {
+		for _, entry := range desktopentry.All() {
+			if entry.AppImageVersion != "" {
+				appImageEntries = append(appImageEntries, entry)
+			}
+		}
+	})
+	for _, entry := range appImageEntries {
+		if SamePath(entry.Command(), appImage) {
+			return entry.AppImageVersion
+		}
+	}
+	return ""
+}
+
+var (
+	versionCacheMu sync.Mutex
+
+	// versionCachePath is a variable so that tests can move the cache
+	versionCachePath = defaultVersionCachePath
+)
+
+// versionCacheKey identifies an executable until it is replaced
+func versionCacheKey(path string) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%s|%d|%d", path, info.Size(), info.ModTime().UnixNano()), true
+}
+
+func readVersionCache() map[string]string {
+	cache := make(map[string]string)
+	if data, err := os.ReadFile(versionCachePath()); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// cachedCommandVersion returns the cached version of an executable and
+// whether it has been run
+func cachedCommandVersion(path string) (string, bool) {
+	key, ok := versionCacheKey(path)
+	if !ok {
+		return "", false
+	}
+	versionCacheMu.Lock()
+	defer versionCacheMu.Unlock()
+	version, ok := readVersionCache()[key]
+	return version, ok
+}
+
+// commandVersion runs the executable with args and returns the first version
+// number in its output. The result, including when there is none, is cached
+// until the executable changes.
+func commandVersion(path string, args []string) string {
+	key, ok := versionCacheKey(path)
+	if !ok {
+		return ""
+	}
+	if version, ok := cachedCommandVersion(path); ok {
+		return version
+	}
+
+	// The executable runs without the lock, so that loading the slicers is
+	// not held up
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.WaitDelay = time.Second
+	cmd.Run()
+	version := versionPattern.FindString(output.String())
+
+	versionCacheMu.Lock()
+	defer versionCacheMu.Unlock()
+	cache := readVersionCache()
+	// Entries of replaced executables are dropped
+	for cached := range cache {
+		if strings.HasPrefix(cached, path+"|") {
+			delete(cache, cached)
+		}
+	}
+	cache[key] = version
+	writeVersionCache(cache)
+	return version
+}
+
+// writeVersionCache replaces the cache file in one step, so that a picker
+// reading it never sees a partial file
+func writeVersionCache(cache map[string]string) {
+	cachePath := versionCachePath()
+	if cachePath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	os.MkdirAll(filepath.Dir(cachePath), 0755)
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), ".versions-*.json")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	os.Rename(tmp.Name(), cachePath)
+}
+
+// compareVersions compares two versions matched by versionPattern by their
+// numbers, so that 2.10.0 > 2.9.1 > 2.9.1-rc2 > 2.9.1-rc1 > 2.9.1-beta3. It
+// returns -1, 0 or 1.
+func compareVersions(a, b string) int {
+	numbersA, preA := splitVersion(a)
+	numbersB, preB := splitVersion(b)
+	for i := 0; i < len(numbersA) || i < len(numbersB); i++ {
+		var na, nb int
+		if i < len(numbersA) {
+			na = numbersA[i]
+		}
+		if i < len(numbersB) {
+			nb = numbersB[i]
+		}
+		if na != nb {
+			return compareInts(na, nb)
+		}
+	}
+
+	// A pre-release comes before the release
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	labelA, numberA := splitNumber(preA)
+	labelB, numberB := splitNumber(preB)
+	if labelA != labelB {
+		return strings.Compare(labelA, labelB)
+	}
+	return compareInts(numberA, numberB)
+}
+
+// splitVersion returns the numbers of a version and its pre-release suffix
+// without separator, e.g. [2 8 0] and "beta1" for 2.8.0-beta1
+func splitVersion(version string) ([]int, string) {
+	core, pre := version, ""
+	if i := strings.IndexAny(version, "abcdefghijklmnopqrstuvwxyz"); i >= 0 {
+		core, pre = strings.TrimRight(version[:i], "-."), version[i:]
+	}
+	var numbers []int
+	for _, field := range strings.Split(core, ".") {
+		n, _ := strconv.Atoi(field)
+		numbers = append(numbers, n)
+	}
+	return numbers, pre
+}
+
+// splitNumber splits the trailing number off a pre-release label like "rc2"
+func splitNumber(label string) (string, int) {
+	prefix := strings.TrimRight(label, "0123456789")
+	n, _ := strconv.Atoi(label[len(prefix):])
+	return prefix, n
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func defaultVersionCachePath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "qslicerpicker", "versions.json")
+}
@@ -0,0 +1,153 @@
+package slicer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestDetectVersion(t *testing.T) {
+	bundle, _ := filepath.Abs(filepath.Join("testdata", "PrusaSlicer.app"))
+	deployDir, _ := filepath.Abs(filepath.Join("testdata", "flatpak"))
+
+	tests := []struct {
+		name string
+		inst Installation
+		want string
+	}{
+		{"Info.plist", Installation{Path: filepath.Join(bundle, "Contents", "MacOS", "PrusaSlicer")}, "2.8.1"},
+		{"Flatpak metainfo", Installation{Path: deployDir, Source: SourceFlatpak, FlatpakID: "com.prusa3d.PrusaSlicer"}, "2.8.1"},
+		{"Flatpak without metainfo", Installation{Path: deployDir, Source: SourceFlatpak, FlatpakID: "com.bambulab.BambuStudio"}, ""},
+		{"PrusaSlicer AppImage", Installation{Path: "/opt/PrusaSlicer-2.6.1+linux-x64-GTK3-202309060711.AppImage"}, "2.6.1"},
+		{"OrcaSlicer AppImage", Installation{Path: "/opt/OrcaSlicer_Linux_V2.0.0.AppImage"}, "2.0.0"},
+		{"Bambu Studio AppImage", Installation{Path: "/opt/Bambu_Studio_linux_ubuntu_v01.09.07.52-20240427.AppImage"}, "01.09.07.52"},
+		{"Cura beta AppImage", Installation{Path: "/opt/UltiMaker-Cura-5.7.0-beta1-linux-X64.AppImage"}, "5.7.0-beta1"},
+	}
+	for _, tt := range tests {
+		if got := detectVersion("prusaslicer", tt.inst); got != tt.want {
+			t.Errorf("%s: detectVersion(%q) = %q, want %q", tt.name, tt.inst.Path, got, tt.want)
+		}
+	}
+}
+
+func TestAppImageEntryVersion(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_DATA_DIRS", t.TempDir())
+
+	appImage := filepath.Join(t.TempDir(), "PrusaSlicer.AppImage")
+	entry := "[Desktop Entry]\nType=Application\nName=PrusaSlicer\nExec=" + appImage + " %F\nX-AppImage-Version=2.7.4\n"
+	if err := os.MkdirAll(filepath.Join(dataHome, "applications"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataHome, "applications", "appimagekit-prusaslicer.desktop"), []byte(entry), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := detectVersion("prusaslicer", Installation{Path: appImage, Source: SourceAppImage}); got != "2.7.4" {
+		t.Errorf("version of a renamed AppImage = %q, want 2.7.4", got)
+	}
+}
+
+// useVersionCache points the version cache at a temporary file
+func useVersionCache(t *testing.T) {
+	t.Helper()
+	cachePath := filepath.Join(t.TempDir(), "versions.json")
+	old := versionCachePath
+	versionCachePath = func() string { return cachePath }
+	t.Cleanup(func() { versionCachePath = old })
+}
+
+func TestCommandVersionIsOnlyRunOnRefresh(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the slicer")
+	}
+	useVersionCache(t)
+
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+	executable := filepath.Join(dir, "prusa-slicer")
+	script := "#!/bin/sh\ntouch '" + marker + "'\necho 'PrusaSlicer-2.8.1+linux-x64-GTK3-202409181416'\n"
+	if err := os.WriteFile(executable, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	inst := Installation{Path: executable, Source: SourcePATH}
+
+	// Loading the slicers never runs them
+	installations := []Installation{inst}
+	DetectVersions("prusaslicer", installations)
+	if installations[0].Version != "" {
+		t.Errorf("version before the refresh = %q, want none", installations[0].Version)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("DetectVersions ran the slicer")
+	}
+
+	args, ok := versionCommand("prusaslicer", inst)
+	if !ok {
+		t.Fatal("no version command for PrusaSlicer")
+	}
+	if got := commandVersion(executable, args); got != "2.8.1" {
+		t.Fatalf("commandVersion() = %q, want 2.8.1", got)
+	}
+
+	// The cached version is used until the executable changes
+	os.Remove(marker)
+	if got := detectVersion("prusaslicer", inst); got != "2.8.1" {
+		t.Errorf("cached version = %q, want 2.8.1", got)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("the cached version ran the slicer again")
+	}
+
+	if err := os.WriteFile(executable, []byte(script+"exit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, cached := cachedCommandVersion(executable); cached {
+		t.Error("version of a replaced executable still cached")
+	}
+}
+
+func TestVersionCommand(t *testing.T) {
+	tests := []struct {
+		id   string
+		inst Installation
+		want bool
+	}{
+		{"prusaslicer", Installation{Path: "/usr/bin/prusa-slicer", Source: SourcePATH}, true},
+		{"prusaslicer", Installation{Path: "/opt/PrusaSlicer.AppImage", Source: SourceAppImage}, false},
+		{"prusaslicer", Installation{Path: "/var/lib/flatpak/app/com.prusa3d.PrusaSlicer", Source: SourceFlatpak, FlatpakID: "com.prusa3d.PrusaSlicer"}, false},
+		{"prusaslicer", Installation{Path: "/Applications/PrusaSlicer.app/Contents/MacOS/PrusaSlicer"}, false},
+		{"cura", Installation{Path: "/usr/bin/cura", Source: SourcePATH}, false},
+	}
+	for _, tt := range tests {
+		if _, got := versionCommand(tt.id, tt.inst); got != tt.want {
+			t.Errorf("versionCommand(%q, %q) = %v, want %v", tt.id, tt.inst.Path, got, tt.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.10.0", "2.9.0", 1},
+		{"2.9.0", "2.10.0", -1},
+		{"2.9", "2.9.0", 0},
+		{"2.9.1", "2.9", 1},
+		{"01.09.07.52", "01.10.00.89", -1},
+		{"5.7.0", "5.7.0-beta1", 1},
+		{"5.7.0-beta1", "5.7.0-beta2", -1},
+		{"5.7.0-beta2", "5.7.0-rc1", -1},
+		{"2.8.0-rc10", "2.8.0-rc9", 1},
+		{"2.8.0-alpha6", "2.8.0alpha6", 0},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package slicer
+
+import (
+	"fmt"
+	"qslicerpicker/internal/config"
+	"qslicerpicker/internal/shellwords"
+	"strings"
+)
+
+// ParseCommands parses wrapper or hook commands, one shell-quoted command per
+// line, e.g. "nice -n 10" or "distrobox enter printing --". Empty lines and
+// lines starting with # are ignored.
+func ParseCommands(text string) ([]config.Arguments, error) {
+	commands := make([]config.Arguments, 0)
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args, err := shellwords.Split(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		commands = append(commands, args)
+	}
+	return commands, nil
+}
+
+// FormatCommands formats commands in the ParseCommands syntax
+func FormatCommands(commands []config.Arguments) string {
+	lines := make([]string, 0, len(commands))
+	for _, command := range commands {
+		lines = append(lines, shellwords.Join(command))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapCommand prefixes a command with wrapper commands. The first wrapper is
+// the outermost; placeholders such as {file} are expanded in each wrapper.
+func wrapCommand(name string, args []string, wrappers []config.Arguments, filePaths []string) (string, []string) {
+	for i := len(wrappers) - 1; i >= 0; i-- {
+		wrapper := expandTemplate(wrappers[i], filePaths, nil)
+		if len(wrapper) == 0 {
+			continue
+		}
+		wrapped := make([]string, 0, len(wrapper)+len(args))
+		wrapped = append(wrapped, wrapper[1:]...)
+		wrapped = append(wrapped, name)
+		args = append(wrapped, args...)
+		name = wrapper[0]
+	}
+	return name, args
+}
@@ -0,0 +1,99 @@
+package slicer
+
+import (
+	"qslicerpicker/internal/config"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCommands(t *testing.T) {
+	tests := []struct {
+		text string
+		want []config.Arguments
+	}{
+		{"", []config.Arguments{}},
+		{"nice -n 10", []config.Arguments{{"nice", "-n", "10"}}},
+		{"# niceness\n\n  nice -n 10  \ngamemoderun\n", []config.Arguments{{"nice", "-n", "10"}, {"gamemoderun"}}},
+		{`distrobox enter "print box" --`, []config.Arguments{{"distrobox", "enter", "print box", "--"}}},
+		{`sh -c 'exec "$0" "$@"'`, []config.Arguments{{"sh", "-c", `exec "$0" "$@"`}}},
+		{`env LABEL=my\ model`, []config.Arguments{{"env", "LABEL=my model"}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseCommands(tt.text)
+		if err != nil {
+			t.Errorf("ParseCommands(%q): %v", tt.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseCommands(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if len(got) > 0 {
+			if again, _ := ParseCommands(FormatCommands(got)); !reflect.DeepEqual(again, got) {
+				t.Errorf("FormatCommands(%q) does not round-trip: %q", got, again)
+			}
+		}
+	}
+}
+
+func TestParseCommandsError(t *testing.T) {
+	_, err := ParseCommands("nice\ndistrobox enter \"print box --\n")
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ParseCommands() = %v, want an error on line 2", err)
+	}
+}
+
+func TestBuildCommand(t *testing.T) {
+	// flatpak is not on the PATH, so its default location is used
+	t.Setenv("PATH", t.TempDir())
+
+	files := []string{"/models/benchy.stl", "/models/my plate.3mf"}
+	prusa := Slicer{ID: "prusaslicer", Path: "/usr/bin/prusa-slicer", Arguments: config.Arguments{"--single-instance"}}
+	flatpak := Slicer{ID: "prusaslicer", FlatpakID: "com.prusa3d.PrusaSlicer", Arguments: config.Arguments{"--single-instance"}}
+
+	tests := []struct {
+		name    string
+		slicer  Slicer
+		global  string
+		wrapper string
+		want    []string
+	}{
+		{
+			"no wrappers", prusa, "", "",
+			[]string{"/usr/bin/prusa-slicer", "--single-instance", "/models/benchy.stl", "/models/my plate.3mf"},
+		},
+		{
+			"global wrapper outside the slicer's", prusa, "nice -n 10", "gamemoderun\nprime-run",
+			[]string{"nice", "-n", "10", "gamemoderun", "prime-run", "/usr/bin/prusa-slicer", "--single-instance", "/models/benchy.stl", "/models/my plate.3mf"},
+		},
+		{
+			"quoted arguments", prusa, "", `distrobox enter "print box" --`,
+			[]string{"distrobox", "enter", "print box", "--", "/usr/bin/prusa-slicer", "--single-instance", "/models/benchy.stl", "/models/my plate.3mf"},
+		},
+		{
+			"file placeholders", prusa, "systemd-run --user --unit=slice-{stem} --working-directory={dir} --", "",
+			[]string{"systemd-run", "--user", "--unit=slice-benchy", "--working-directory=/models", "--", "/usr/bin/prusa-slicer", "--single-instance", "/models/benchy.stl", "/models/my plate.3mf"},
+		},
+		{
+			"Flatpak", flatpak, "nice -n 10", "systemd-inhibit --why={basename}",
+			[]string{"nice", "-n", "10", "systemd-inhibit", "--why=benchy.stl", "/usr/bin/flatpak", "run", "--file-forwarding", "com.prusa3d.PrusaSlicer",
+				"--single-instance", "@@", "/models/benchy.stl", "/models/my plate.3mf", "@@"},
+		},
+	}
+	for _, tt := range tests {
+		global, err := ParseCommands(tt.global)
+		if err != nil {
+			t.Fatal(err)
+		}
+		withConfig(t, func(cfg *config.Config) { cfg.Wrappers = global })
+		s := tt.slicer
+		if s.Wrappers, err = ParseCommands(tt.wrapper); err != nil {
+			t.Fatal(err)
+		}
+
+		name, args := buildCommand(s, files)
+		if got := append([]string{name}, args...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: command = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
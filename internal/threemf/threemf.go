@@ -0,0 +1,84 @@
+package threemf
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+)
+
+// Package relationship types
+const (
+	ModelRelationship     = "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"
+	ThumbnailRelationship = "http://schemas.openxmlformats.org/package/2006/relationships/metadata/thumbnail"
+)
+
+const (
+	relsPart         = "_rels/.rels"
+	defaultModelPart = "3D/3dmodel.model"
+	maxRelsLen       = 1 << 20
+)
+
+// Package is an open 3MF package
+type Package struct {
+	*zip.ReadCloser
+	Parts map[string]*zip.File // by part name, without the leading slash
+}
+
+// Open opens a 3MF package
+func Open(filePath string) (*Package, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	parts := make(map[string]*zip.File, len(reader.File))
+	for _, file := range reader.File {
+		parts[strings.TrimPrefix(file.Name, "/")] = file
+	}
+	return &Package{ReadCloser: reader, Parts: parts}, nil
+}
+
+// ModelPart returns the root model part named in the package relationships
+func (p *Package) ModelPart() string {
+	if part := p.RelationshipTarget(ModelRelationship); part != "" {
+		return part
+	}
+	return defaultModelPart
+}
+
+// RelationshipTarget returns the part targeted by the first package
+// relationship of the given type, or ""
+func (p *Package) RelationshipTarget(relationshipType string) string {
+	file, ok := p.Parts[relsPart]
+	if !ok {
+		return ""
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+
+	var rels struct {
+		Relationships []struct {
+			Target string `xml:"Target,attr"`
+			Type   string `xml:"Type,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(rc, maxRelsLen)).Decode(&rels); err != nil {
+		return ""
+	}
+	for _, rel := range rels.Relationships {
+		if rel.Type == relationshipType {
+			return PartName(rel.Target)
+		}
+	}
+	return ""
+}
+
+// PartName converts a package target such as "/3D/3dmodel.model" to a part
+// name
+func PartName(target string) string {
+	return strings.TrimPrefix(path.Clean("/"+target), "/")
+}
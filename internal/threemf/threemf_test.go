@@ -0,0 +1,75 @@
+package threemf
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writePackage creates a 3MF package with the given parts
+func writePackage(t *testing.T, parts map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "model.3mf")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zw := zip.NewWriter(file)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRelationships(t *testing.T) {
+	path := writePackage(t, map[string]string{
+		"_rels/.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Target="/Metadata/preview.png" Id="rel-2" Type="` + ThumbnailRelationship + `"/>
+  <Relationship Target="/3D/Objects/../main.model" Id="rel-1" Type="` + ModelRelationship + `"/>
+</Relationships>`,
+		"3D/main.model":         "<model/>",
+		"/Metadata/preview.png": "",
+	})
+	pkg, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pkg.Close()
+
+	if part := pkg.ModelPart(); part != "3D/main.model" {
+		t.Errorf("ModelPart() = %q, want 3D/main.model", part)
+	}
+	if part := pkg.RelationshipTarget(ThumbnailRelationship); part != "Metadata/preview.png" {
+		t.Errorf("thumbnail = %q, want Metadata/preview.png", part)
+	}
+	if _, ok := pkg.Parts["Metadata/preview.png"]; !ok {
+		t.Error("part names keep their leading slash")
+	}
+}
+
+func TestDefaultModelPart(t *testing.T) {
+	for name, parts := range map[string]map[string]string{
+		"no relationships":      {"3D/3dmodel.model": "<model/>"},
+		"broken relationships":  {"_rels/.rels": "<Relationships", "3D/3dmodel.model": "<model/>"},
+		"no model relationship": {"_rels/.rels": "<Relationships/>", "3D/3dmodel.model": "<model/>"},
+	} {
+		pkg, err := Open(writePackage(t, parts))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if part := pkg.ModelPart(); part != defaultModelPart {
+			t.Errorf("%s: ModelPart() = %q, want %q", name, part, defaultModelPart)
+		}
+		pkg.Close()
+	}
+}
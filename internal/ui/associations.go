@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"qslicerpicker/internal/config"
+	"qslicerpicker/internal/i18n"
+	"qslicerpicker/internal/slicer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// createAssociationsTab creates the tab listing slicers remembered from the
+// selector, each of which can be revoked
+func createAssociationsTab() fyne.CanvasObject {
+	cfg := config.GetConfig()
+
+	slicerNames := make(map[string]string)
+	for _, s := range slicer.LoadSlicers() {
+		slicerNames[s.ID] = s.Name
+	}
+
+	var list *widget.List
+	list = widget.NewList(
+		func() int {
+			return len(cfg.Associations)
+		},
+		func() fyne.CanvasObject {
+			nameLabel := widget.NewLabel("")
+			nameLabel.Truncation = fyne.TextTruncateEllipsis
+			deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
+
+			// Layout: Description | Delete
+			return container.NewBorder(nil, nil, nil, deleteBtn, nameLabel)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(cfg.Associations) {
+				return
+			}
+
+			association := cfg.Associations[id]
+			borderContainer := obj.(*fyne.Container)
+
+			var nameLabel *widget.Label
+			var deleteBtn *widget.Button
+			for _, obj := range borderContainer.Objects {
+				if label, ok := obj.(*widget.Label); ok {
+					nameLabel = label
+				} else if button, ok := obj.(*widget.Button); ok {
+					deleteBtn = button
+				}
+			}
+
+			if nameLabel == nil || deleteBtn == nil {
+				return
+			}
+
+			target := association.Directory
+			if target == "" {
+				target = "*." + association.Extension
+			}
+			name := association.Slicer
+			if slicerName, ok := slicerNames[name]; ok {
+				name = slicerName
+			}
+			nameLabel.SetText(target + " → " + name)
+
+			deleteBtn.OnTapped = func() {
+				cfg.Associations = append(cfg.Associations[:id], cfg.Associations[id+1:]...)
+				config.SaveConfig()
+				list.Refresh()
+			}
+		},
+	)
+
+	hint := widget.NewLabel(i18n.T("remembered_hint"))
+	hint.Wrapping = fyne.TextWrapWord
+
+	return container.NewBorder(hint, nil, nil, nil, list)
+}
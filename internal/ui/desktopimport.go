@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"fmt"
+	"qslicerpicker/internal/config"
+	"qslicerpicker/internal/i18n"
+	"qslicerpicker/internal/slicer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showDesktopImportDialog lists the slicers found in the installed
+// applications' .desktop entries and imports the chosen ones: entries of a
+// default slicer set its path, other applications become custom slicers
+func showDesktopImportDialog() {
+	if settingsWindow == nil {
+		return
+	}
+
+	slicers := slicer.LoadSlicers()
+	apps := slicer.DesktopApps()
+	if len(apps) == 0 {
+		dialog.ShowInformation(i18n.T("import_applications"), i18n.T("no_applications_found"), settingsWindow)
+		return
+	}
+
+	checks := make([]*widget.Check, len(apps))
+	rows := container.NewVBox()
+	for i, app := range apps {
+		target := app.Path
+		if app.FlatpakID != "" {
+			target = app.FlatpakID
+		}
+		text := fmt.Sprintf("%s — %s", app.Name, target)
+		if known := findSlicer(slicers, app.ID); known != nil && known.Name != app.Name {
+			text = fmt.Sprintf("%s (%s) — %s", known.Name, app.Name, target)
+		}
+
+		check := widget.NewCheck(text, nil)
+		if imported(slicers, app) {
+			check.Text = text + " — " + i18n.T("already_added")
+			check.Disable()
+		} else {
+			// Unknown applications only claim to open models, so they
+			// are not ticked by default
+			check.SetChecked(app.ID != "")
+		}
+		checks[i] = check
+		rows.Add(check)
+	}
+
+	hint := widget.NewLabel(i18n.T("import_applications_hint"))
+	hint.Wrapping = fyne.TextWrapWord
+
+	d := dialog.NewCustomConfirm(i18n.T("import_applications"), i18n.T("import"), i18n.T("cancel"),
+		container.NewBorder(hint, nil, nil, nil, container.NewVScroll(rows)),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			var chosen []slicer.DesktopApp
+			for i, check := range checks {
+				if check.Checked && !check.Disabled() {
+					chosen = append(chosen, apps[i])
+				}
+			}
+			if len(chosen) == 0 {
+				return
+			}
+			importDesktopApps(chosen)
+			if settingsWindow != nil {
+				settingsWindow.SetContent(createSettingsContent())
+			}
+		}, settingsWindow)
+	d.Resize(fyne.NewSize(600, 450))
+	d.Show()
+}
+
+// importDesktopApps stores the applications in the config. A default slicer
+// keeps its own arguments and environment if it has any, and only gets a
+// custom path when discovery does not find the application, so that it keeps
+// following upgrades and separately listed versions.
+func importDesktopApps(apps []slicer.DesktopApp) {
+	cfg := config.GetConfig()
+	for _, app := range apps {
+		if app.ID == "" {
+			cfg.CustomSlicers = append(cfg.CustomSlicers, config.CustomSlicer{
+				Name:        app.Name,
+				Path:        app.Path,
+				Arguments:   app.Arguments,
+				FlatpakID:   app.FlatpakID,
+				SingleFile:  app.SingleFile,
+				Environment: app.Environment,
+				Enabled:     true,
+				Order:       len(cfg.CustomSlicers) * 10,
+			})
+			continue
+		}
+
+		index := -1
+		for i := range cfg.Slicers {
+			if cfg.Slicers[i].ID == app.ID {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			cfg.Slicers = append(cfg.Slicers, config.SlicerConfig{ID: app.ID, Enabled: true})
+			index = len(cfg.Slicers) - 1
+		}
+		sc := &cfg.Slicers[index]
+		if !app.Discovered() {
+			sc.CustomPath, sc.FlatpakID = app.Path, app.FlatpakID
+		}
+		if app.SingleFile {
+			sc.SingleFile = true
+		}
+		if len(sc.Arguments) == 0 {
+			sc.Arguments = app.Arguments
+		}
+		if len(sc.Environment) == 0 {
+			sc.Environment = app.Environment
+		}
+		sc.Enabled = true
+	}
+	config.SaveConfig()
+}
+
+// imported reports whether a slicer already runs the application
+func imported(slicers []slicer.Slicer, app slicer.DesktopApp) bool {
+	for _, s := range slicers {
+		if app.ID != "" && s.BaseID() != app.ID {
+			continue
+		}
+		if app.FlatpakID != "" {
+			if s.FlatpakID == app.FlatpakID {
+				return true
+			}
+			continue
+		}
+		if slicer.SamePath(s.Path, app.Path) {
+			return true
+		}
+		for _, inst := range s.Installations {
+			if inst.FlatpakID == "" && slicer.SamePath(inst.Path, app.Path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func findSlicer(slicers []slicer.Slicer, id string) *slicer.Slicer {
+	if id == "" {
+		return nil
+	}
+	for i := range slicers {
+		if slicers[i].ID == id {
+			return &slicers[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"qslicerpicker/internal/i18n"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// loopStopped is closed when the event loop run by RunInBackground ends. The
+// selector waits for it instead of running the loop itself.
+var loopStopped chan struct{}
+
+// RunInBackground runs fn while the app's event loop runs on the calling
+// goroutine, which must be the main one, so that fn can show windows before
+// the selector. The app quits when fn returns.
+func RunInBackground(fyneApp fyne.App, fn func()) {
+	loopStopped = make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer fyneApp.Quit()
+		fn()
+	}()
+	fyneApp.Run()
+	close(loopStopped)
+	<-done
+}
+
+// ShowDownload shows a progress window with a cancel button while download
+// runs; it must be called from the function passed to RunInBackground. A
+// failed download is shown in an error dialog, and ShowDownload returns once
+// it has been dismissed. The window is hidden after a successful download.
+func ShowDownload(fyneApp fyne.App, label string, download func(ctx context.Context) ([]string, error)) ([]string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	win := fyneApp.NewWindow(i18n.T("downloading_model"))
+	win.Resize(fyne.NewSize(420, 0))
+	win.CenterOnScreen()
+	win.SetCloseIntercept(cancel)
+
+	message := widget.NewLabel(label)
+	message.Wrapping = fyne.TextWrapBreak
+	cancelBtn := widget.NewButton(i18n.T("cancel"), cancel)
+	win.SetContent(container.NewPadded(container.NewVBox(
+		message,
+		widget.NewProgressBarInfinite(),
+		container.NewCenter(cancelBtn),
+	)))
+	win.Show()
+
+	files, err := download(ctx)
+	if err == nil {
+		win.Hide()
+		return files, nil
+	}
+	if errors.Is(err, context.Canceled) {
+		win.Close()
+		return nil, err
+	}
+
+	// Failures are reported like failed launches
+	cancelBtn.Disable()
+	dismissed := make(chan struct{})
+	var once sync.Once
+	dismiss := func() { once.Do(func() { close(dismissed) }) }
+	win.SetCloseIntercept(dismiss)
+	showErrorDetails(win, i18n.T("error_downloading"), err, dismiss)
+	<-dismissed
+	win.Close()
+	return nil, err
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"qslicerpicker/internal/icons"
+	"qslicerpicker/internal/slicer"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// Icons are resolved once per process; finding some of them runs the slicer
+// (AppImages) or scans the installed applications
+var (
+	iconsMu    sync.Mutex
+	iconsCache = make(map[string]fyne.Resource)
+)
+
+func iconKey(s slicer.Slicer) string {
+	return s.ID + "|" + s.Path + "|" + s.FlatpakID + "|" + s.Icon
+}
+
+// slicerIcon returns the resolved icon of a slicer, a generic application
+// icon if it has none, or nil while it is still being resolved
+func slicerIcon(s slicer.Slicer) fyne.Resource {
+	iconsMu.Lock()
+	defer iconsMu.Unlock()
+	return iconsCache[iconKey(s)]
+}
+
+// loadSlicerIcons resolves the icons that are not cached yet in the
+// background and calls refresh when any were added
+func loadSlicerIcons(slicers []slicer.Slicer, refresh func()) {
+	var missing []slicer.Slicer
+	iconsMu.Lock()
+	for _, s := range slicers {
+		if _, ok := iconsCache[iconKey(s)]; !ok {
+			// Claimed so that another list does not resolve it again
+			iconsCache[iconKey(s)] = nil
+			missing = append(missing, s)
+		}
+	}
+	iconsMu.Unlock()
+	if len(missing) == 0 {
+		return
+	}
+
+	go func() {
+		for _, s := range missing {
+			var res fyne.Resource = theme.FileApplicationIcon()
+			if icon, ok := icons.ForSlicer(s); ok {
+				res = fyne.NewStaticResource(icon.Name, icon.Data)
+			}
+			iconsMu.Lock()
+			iconsCache[iconKey(s)] = res
+			iconsMu.Unlock()
+		}
+		refresh()
+	}()
+}
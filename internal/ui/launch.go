@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"fmt"
+	"qslicerpicker/internal/config"
+	"qslicerpicker/internal/i18n"
+	"qslicerpicker/internal/slicer"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// createLaunchTab creates the tab with settings that apply to every launch
+func createLaunchTab() fyne.CanvasObject {
+	cfg := config.GetConfig()
+
+	graceEntry := widget.NewEntry()
+	graceEntry.SetPlaceHolder(strconv.Itoa(int(slicer.DefaultGracePeriod.Seconds())))
+	if cfg.LaunchGraceSeconds > 0 {
+		graceEntry.SetText(strconv.Itoa(cfg.LaunchGraceSeconds))
+	}
+
+	countdownEntry := widget.NewEntry()
+	countdownEntry.SetPlaceHolder("0")
+	if cfg.CountdownSeconds > 0 {
+		countdownEntry.SetText(strconv.Itoa(cfg.CountdownSeconds))
+	}
+
+	extensionCountdownsEntry := widget.NewMultiLineEntry()
+	extensionCountdownsEntry.SetPlaceHolder("3mf = 5\ngcode = 0")
+	extensionCountdownsEntry.SetMinRowsVisible(2)
+	extensionCountdownsEntry.SetText(formatExtensionCountdowns(cfg.ExtensionCountdowns))
+
+	wrappersEntry := widget.NewMultiLineEntry()
+	wrappersEntry.SetPlaceHolder("nice -n 10\nsystemd-run --user --scope -p MemoryMax=8G")
+	wrappersEntry.SetMinRowsVisible(3)
+	wrappersEntry.SetText(slicer.FormatCommands(cfg.Wrappers))
+
+	preLaunchEntry := widget.NewMultiLineEntry()
+	preLaunchEntry.SetMinRowsVisible(2)
+	preLaunchEntry.SetText(slicer.FormatCommands(cfg.Hooks.PreLaunch))
+
+	postExitEntry := widget.NewMultiLineEntry()
+	postExitEntry.SetMinRowsVisible(2)
+	postExitEntry.SetText(slicer.FormatCommands(cfg.Hooks.PostExit))
+
+	saveBtn := widget.NewButton(i18n.T("save"), func() {
+		grace := 0
+		if text := strings.TrimSpace(graceEntry.Text); text != "" {
+			value, err := strconv.Atoi(text)
+			if err != nil || value < 0 {
+				dialog.ShowError(fmt.Errorf("%s: %q", i18n.T("launch_grace_seconds"), text), settingsWindow)
+				return
+			}
+			grace = value
+		}
+
+		countdown := 0
+		if text := strings.TrimSpace(countdownEntry.Text); text != "" {
+			value, err := strconv.Atoi(text)
+			if err != nil || value < 0 {
+				dialog.ShowError(fmt.Errorf("%s: %q", i18n.T("countdown_seconds"), text), settingsWindow)
+				return
+			}
+			countdown = value
+		}
+
+		extensionCountdowns, err := parseExtensionCountdowns(extensionCountdownsEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("extension_countdowns"), err), settingsWindow)
+			return
+		}
+
+		wrappers, err := slicer.ParseCommands(wrappersEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("wrappers"), err), settingsWindow)
+			return
+		}
+
+		preLaunch, err := slicer.ParseCommands(preLaunchEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("pre_launch_hooks"), err), settingsWindow)
+			return
+		}
+
+		postExit, err := slicer.ParseCommands(postExitEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("post_exit_hooks"), err), settingsWindow)
+			return
+		}
+
+		cfg.LaunchGraceSeconds = grace
+		cfg.CountdownSeconds = countdown
+		cfg.ExtensionCountdowns = extensionCountdowns
+		cfg.Wrappers = wrappers
+		cfg.Hooks = config.Hooks{PreLaunch: preLaunch, PostExit: postExit}
+		config.SaveConfig()
+	})
+	saveBtn.Importance = widget.HighImportance
+
+	countdownHint := widget.NewLabel(i18n.T("countdown_hint"))
+	countdownHint.Wrapping = fyne.TextWrapWord
+
+	wrappersHint := widget.NewLabel(i18n.T("wrappers_hint"))
+	wrappersHint.Wrapping = fyne.TextWrapWord
+
+	hooksHint := widget.NewLabel(i18n.T("hooks_hint"))
+	hooksHint.Wrapping = fyne.TextWrapWord
+
+	return container.NewVScroll(container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem(i18n.T("countdown_seconds"), countdownEntry),
+			widget.NewFormItem(i18n.T("extension_countdowns"), extensionCountdownsEntry),
+		),
+		countdownHint,
+		widget.NewForm(
+			widget.NewFormItem(i18n.T("launch_grace_seconds"), graceEntry),
+			widget.NewFormItem(i18n.T("wrappers"), wrappersEntry),
+		),
+		wrappersHint,
+		widget.NewForm(
+			widget.NewFormItem(i18n.T("pre_launch_hooks"), preLaunchEntry),
+			widget.NewFormItem(i18n.T("post_exit_hooks"), postExitEntry),
+		),
+		hooksHint,
+		saveBtn,
+	))
+}
+
+// parseExtensionCountdowns parses one "extension = seconds" pair per line
+func parseExtensionCountdowns(text string) (map[string]int, error) {
+	countdowns := make(map[string]int)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		ext, value, ok := strings.Cut(line, "=")
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		seconds, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || ext == "" || err != nil || seconds < 0 {
+			return nil, fmt.Errorf("invalid line %q", line)
+		}
+		countdowns[ext] = seconds
+	}
+	if len(countdowns) == 0 {
+		return nil, nil
+	}
+	return countdowns, nil
+}
+
+// formatExtensionCountdowns is the inverse of parseExtensionCountdowns
+func formatExtensionCountdowns(countdowns map[string]int) string {
+	extensions := make([]string, 0, len(countdowns))
+	for ext := range countdowns {
+		extensions = append(extensions, ext)
+	}
+	sort.Strings(extensions)
+
+	lines := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		lines = append(lines, fmt.Sprintf("%s = %d", ext, countdowns[ext]))
+	}
+	return strings.Join(lines, "\n")
+}
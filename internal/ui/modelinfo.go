@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"qslicerpicker/internal/i18n"
+	"qslicerpicker/internal/mesh"
+	"strconv"
+	"strings"
+)
+
+// describeModel returns the file name and size followed, if the mesh was
+// loaded, by its dimensions, counts, volume and 3MF build, one per line
+func describeModel(filePath string, m *mesh.Mesh) string {
+	lines := []string{filepath.Base(filePath)}
+	if info, err := os.Stat(filePath); err == nil {
+		lines = append(lines, formatFileSize(info.Size()))
+	}
+	if m == nil {
+		return strings.Join(lines, "\n")
+	}
+
+	size := m.Size()
+	lines = append(lines,
+		fmt.Sprintf(i18n.T("model_dimensions"), size[0], size[1], size[2]),
+		fmt.Sprintf(i18n.T("model_triangles"), formatCount(len(m.Triangles))),
+		fmt.Sprintf(i18n.T("model_vertices"), formatCount(len(m.Vertices))),
+		// mm³ to cm³
+		fmt.Sprintf(i18n.T("model_volume"), m.Volume()/1000),
+	)
+	if m.BuildItems > 0 {
+		lines = append(lines, fmt.Sprintf(i18n.T("model_build"), m.BuildItems, m.Objects))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatFileSize returns a size such as "12.4 MB"
+func formatFileSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size) / unit
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		if value < unit || suffix == "GB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return ""
+}
+
+// formatCount groups the digits of n in threes, e.g. "1 234 567"
+func formatCount(n int) string {
+	digits := strconv.Itoa(n)
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteRune(' ')
+		}
+		grouped.WriteRune(digit)
+	}
+	return grouped.String()
+}
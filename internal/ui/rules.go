@@ -0,0 +1,318 @@
+package ui
+
+import (
+	"fmt"
+	"qslicerpicker/internal/config"
+	"qslicerpicker/internal/i18n"
+	"qslicerpicker/internal/rules"
+	"qslicerpicker/internal/slicer"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// createRulesTab creates the tab listing the rules that pick a slicer
+// without showing the selector
+func createRulesTab() fyne.CanvasObject {
+	cfg := config.GetConfig()
+
+	slicerNames := make(map[string]string)
+	for _, s := range slicer.LoadSlicers() {
+		slicerNames[s.ID] = s.Name
+	}
+
+	var list *widget.List
+	list = widget.NewList(
+		func() int {
+			return len(cfg.Rules)
+		},
+		func() fyne.CanvasObject {
+			checkbox := widget.NewCheck("", nil)
+			nameLabel := widget.NewLabel("")
+			nameLabel.Truncation = fyne.TextTruncateEllipsis
+			editBtn := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil)
+			upBtn := widget.NewButtonWithIcon("", theme.MoveUpIcon(), nil)
+			downBtn := widget.NewButtonWithIcon("", theme.MoveDownIcon(), nil)
+			deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
+
+			// Layout: Checkbox | Description | Edit | Up | Down | Delete
+			return container.NewBorder(
+				nil, nil,
+				checkbox,
+				container.NewHBox(editBtn, upBtn, downBtn, deleteBtn),
+				nameLabel,
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(cfg.Rules) {
+				return
+			}
+
+			rule := cfg.Rules[id]
+			borderContainer := obj.(*fyne.Container)
+
+			var checkbox *widget.Check
+			var buttons *fyne.Container
+			var nameLabel *widget.Label
+
+			for _, obj := range borderContainer.Objects {
+				if check, ok := obj.(*widget.Check); ok {
+					checkbox = check
+				} else if label, ok := obj.(*widget.Label); ok {
+					nameLabel = label
+				} else if cont, ok := obj.(*fyne.Container); ok {
+					buttons = cont
+				}
+			}
+
+			if checkbox == nil || buttons == nil || nameLabel == nil {
+				return
+			}
+
+			editBtn := buttons.Objects[0].(*widget.Button)
+			upBtn := buttons.Objects[1].(*widget.Button)
+			downBtn := buttons.Objects[2].(*widget.Button)
+			deleteBtn := buttons.Objects[3].(*widget.Button)
+
+			nameLabel.SetText(describeRule(rule, slicerNames))
+			checkbox.SetChecked(!rule.Disabled)
+
+			checkbox.OnChanged = func(checked bool) {
+				if cfg.Rules[id].Disabled == !checked {
+					return
+				}
+				cfg.Rules[id].Disabled = !checked
+				config.SaveConfig()
+			}
+
+			editBtn.OnTapped = func() {
+				showRuleDialog(&rule, func(updated config.Rule) {
+					cfg.Rules[id] = updated
+					config.SaveConfig()
+					list.Refresh()
+				})
+			}
+
+			upBtn.OnTapped = func() {
+				if id > 0 {
+					cfg.Rules[id-1], cfg.Rules[id] = cfg.Rules[id], cfg.Rules[id-1]
+					config.SaveConfig()
+					list.Refresh()
+				}
+			}
+
+			downBtn.OnTapped = func() {
+				if id < len(cfg.Rules)-1 {
+					cfg.Rules[id+1], cfg.Rules[id] = cfg.Rules[id], cfg.Rules[id+1]
+					config.SaveConfig()
+					list.Refresh()
+				}
+			}
+
+			deleteBtn.OnTapped = func() {
+				cfg.Rules = append(cfg.Rules[:id], cfg.Rules[id+1:]...)
+				config.SaveConfig()
+				list.Refresh()
+			}
+
+			upBtn.Disable()
+			if id > 0 {
+				upBtn.Enable()
+			}
+
+			downBtn.Disable()
+			if id < len(cfg.Rules)-1 {
+				downBtn.Enable()
+			}
+		},
+	)
+
+	addBtn := widget.NewButton(i18n.T("add_rule"), func() {
+		showRuleDialog(nil, func(rule config.Rule) {
+			cfg.Rules = append(cfg.Rules, rule)
+			config.SaveConfig()
+			list.Refresh()
+		})
+	})
+
+	hint := widget.NewLabel(i18n.T("rules_hint"))
+	hint.Wrapping = fyne.TextWrapWord
+
+	return container.NewBorder(
+		hint,
+		addBtn,
+		nil, nil,
+		list,
+	)
+}
+
+// describeRule returns a one-line summary such as "*.bgcode → PrusaSlicer"
+func describeRule(rule config.Rule, slicerNames map[string]string) string {
+	target := rule.Slicer
+	if target == config.RuleAsk {
+		target = i18n.T("always_ask")
+	} else if name, ok := slicerNames[target]; ok {
+		target = name
+	} else if target != "" {
+		target = fmt.Sprintf(i18n.T("missing_slicer"), target)
+	}
+
+	if rule.Name != "" {
+		return rule.Name + " → " + target
+	}
+
+	var conditions []string
+	if len(rule.Extensions) > 0 {
+		conditions = append(conditions, "*."+strings.Join(rule.Extensions, ", *."))
+	}
+	if rule.Glob != "" {
+		conditions = append(conditions, rule.Glob)
+	}
+	if rule.Directory != "" {
+		conditions = append(conditions, rule.Directory)
+	}
+	if rule.MinSize > 0 {
+		conditions = append(conditions, "≥ "+rules.FormatSize(rule.MinSize))
+	}
+	if rule.MaxSize > 0 {
+		conditions = append(conditions, "≤ "+rules.FormatSize(rule.MaxSize))
+	}
+	if rule.MIMEType != "" {
+		conditions = append(conditions, rule.MIMEType)
+	}
+	if len(conditions) == 0 {
+		conditions = append(conditions, "*")
+	}
+	return strings.Join(conditions, " ") + " → " + target
+}
+
+func showRuleDialog(rule *config.Rule, onSave func(rule config.Rule)) {
+	if settingsWindow == nil {
+		return
+	}
+
+	title := i18n.T("add_rule")
+	if rule != nil {
+		title = i18n.T("rules")
+	}
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder(i18n.T("name"))
+
+	extensionsEntry := widget.NewEntry()
+	extensionsEntry.SetPlaceHolder("bgcode, gcode")
+
+	globEntry := widget.NewEntry()
+	globEntry.SetPlaceHolder("*_pla.3mf")
+
+	dirEntry := widget.NewEntry()
+	dirEntry.SetPlaceHolder("~/bambu")
+
+	minSizeEntry := widget.NewEntry()
+	minSizeEntry.SetPlaceHolder("0")
+
+	maxSizeEntry := widget.NewEntry()
+	maxSizeEntry.SetPlaceHolder("50M")
+
+	mimeEntry := widget.NewEntry()
+	mimeEntry.SetPlaceHolder("model/3mf")
+
+	// The first option always shows the selector
+	slicers := slicer.LoadSlicers()
+	options := []string{i18n.T("always_ask")}
+	ids := []string{config.RuleAsk}
+	for _, s := range slicers {
+		options = append(options, s.Name)
+		ids = append(ids, s.ID)
+	}
+	slicerSelect := widget.NewSelect(options, nil)
+	slicerSelect.SetSelectedIndex(0)
+
+	if rule != nil {
+		nameEntry.SetText(rule.Name)
+		extensionsEntry.SetText(strings.Join(rule.Extensions, ", "))
+		globEntry.SetText(rule.Glob)
+		dirEntry.SetText(rule.Directory)
+		minSizeEntry.SetText(rules.FormatSize(rule.MinSize))
+		maxSizeEntry.SetText(rules.FormatSize(rule.MaxSize))
+		mimeEntry.SetText(rule.MIMEType)
+		found := false
+		for i, id := range ids {
+			if id == rule.Slicer {
+				slicerSelect.SetSelectedIndex(i)
+				found = true
+				break
+			}
+		}
+		// A slicer that is no longer installed is kept unless changed
+		if !found && rule.Slicer != "" {
+			ids = append(ids, rule.Slicer)
+			slicerSelect.Options = append(slicerSelect.Options, fmt.Sprintf(i18n.T("missing_slicer"), rule.Slicer))
+			slicerSelect.SetSelectedIndex(len(ids) - 1)
+		}
+	}
+
+	browseDirBtn := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			dirEntry.SetText(uri.Path())
+		}, settingsWindow)
+	})
+
+	var d dialog.Dialog
+
+	saveBtn := widget.NewButton(i18n.T("save"), func() {
+		minSize, err := rules.ParseSize(minSizeEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("rule_min_size"), err), settingsWindow)
+			return
+		}
+		maxSize, err := rules.ParseSize(maxSizeEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("rule_max_size"), err), settingsWindow)
+			return
+		}
+
+		updated := config.Rule{
+			Name:       strings.TrimSpace(nameEntry.Text),
+			Extensions: parseExtensions(extensionsEntry.Text),
+			Glob:       strings.TrimSpace(globEntry.Text),
+			Directory:  strings.TrimSpace(dirEntry.Text),
+			MinSize:    minSize,
+			MaxSize:    maxSize,
+			MIMEType:   strings.TrimSpace(mimeEntry.Text),
+			Slicer:     ids[slicerSelect.SelectedIndex()],
+		}
+		if rule != nil {
+			updated.Disabled = rule.Disabled
+		}
+
+		onSave(updated)
+		d.Hide()
+	})
+	saveBtn.Importance = widget.HighImportance
+
+	content := container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem(i18n.T("name"), nameEntry),
+			widget.NewFormItem(i18n.T("rule_extensions"), extensionsEntry),
+			widget.NewFormItem(i18n.T("rule_glob"), globEntry),
+			widget.NewFormItem(i18n.T("rule_directory"), container.NewBorder(nil, nil, nil, browseDirBtn, dirEntry)),
+			widget.NewFormItem(i18n.T("rule_min_size"), minSizeEntry),
+			widget.NewFormItem(i18n.T("rule_max_size"), maxSizeEntry),
+			widget.NewFormItem(i18n.T("rule_mime_type"), mimeEntry),
+			widget.NewFormItem(i18n.T("rule_slicer"), slicerSelect),
+		),
+		saveBtn,
+	)
+
+	d = dialog.NewCustom(title, i18n.T("cancel"), content, settingsWindow)
+	d.Resize(fyne.NewSize(500, 450))
+	d.Show()
+}
@@ -1,59 +1,279 @@
 package ui
 
 import (
+	"fmt"
+	"image/color"
+	"math"
+	"path/filepath"
 	"qslicerpicker/internal/i18n"
+	"qslicerpicker/internal/preview"
+	"qslicerpicker/internal/rules"
 	"qslicerpicker/internal/slicer"
+	"strings"
+	"sync"
+	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
+// SelectorRequest describes what the slicer selector should offer
+type SelectorRequest struct {
+	Files   []string
+	Slicers []slicer.Slicer
+
+	// Preferred is the ID of the slicer to pre-select and highlight, shown
+	// with PreferredNote, e.g. the slicer that created the file
+	Preferred     string
+	PreferredNote string
+
+	// HideUnsupported hides slicers that cannot open the files instead of
+	// greying them out
+	HideUnsupported bool
+
+	// Countdown, if positive, opens the pre-selected slicer after this long
+	// unless the user presses a key or clicks first
+	Countdown time.Duration
+
+	// Incoming delivers files forwarded by later invocations of the picker,
+	// which are merged into the open window
+	Incoming <-chan []string
+
+	// Launch, if set, is called when the user confirms a choice. If it fails
+	// the error is shown and the window stays open for another choice.
+	Launch func(*Selection) error
+}
+
+// Selection is the result of the slicer selector
+type Selection struct {
+	Slicer *slicer.Slicer
+	Files  []string
+
+	// The extension and directory the user asked to always open with the
+	// slicer, empty if not requested
+	RememberExtension string
+	RememberDirectory string
+}
+
 // ShowSlicerSelector shows a dialog to select a slicer (uses main app)
-func ShowSlicerSelector(filePath string, slicers []slicer.Slicer) *slicer.Slicer {
+func ShowSlicerSelector(req SelectorRequest) *Selection {
 	app := GetApp()
-	return ShowSlicerSelectorWithApp(app, filePath, slicers)
+	return ShowSlicerSelectorWithApp(app, req)
 }
 
 // ShowSlicerSelectorWithApp shows a dialog to select a slicer with a specific app instance
-func ShowSlicerSelectorWithApp(fyneApp fyne.App, filePath string, slicers []slicer.Slicer) *slicer.Slicer {
+func ShowSlicerSelectorWithApp(fyneApp fyne.App, req SelectorRequest) *Selection {
+	slicers := req.Slicers
 	if len(slicers) == 0 {
 		return nil
 	}
 
-	resultChan := make(chan *slicer.Slicer, 1)
+	resultChan := make(chan *Selection, 1)
 	var selectedSlicer *slicer.Slicer
 
+	// Files may grow while the window is open, which changes the slicers
+	// that can open them
+	var mu sync.Mutex
+	files := append([]string(nil), req.Files...)
+	filter := ""                     // lower-case text typed into the filter
+	var shown []int                  // indexes of the listed slicers
+	var unsupported map[int][]string // extensions each slicer cannot open
+	updateSupport := func() {
+		unsupported = make(map[int][]string)
+		for i := range slicers {
+			if extensions := slicers[i].UnsupportedExtensions(files); len(extensions) > 0 {
+				unsupported[i] = extensions
+			}
+		}
+		// Offer everything rather than nothing
+		if len(unsupported) == len(slicers) {
+			unsupported = nil
+		}
+		shown = shown[:0]
+		for i := range slicers {
+			if req.HideUnsupported && unsupported[i] != nil {
+				continue
+			}
+			if filter != "" && !strings.Contains(strings.ToLower(slicers[i].Name), filter) {
+				continue
+			}
+			shown = append(shown, i)
+		}
+	}
+	updateSupport()
+	currentFiles := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), files...)
+	}
+
 	win := fyneApp.NewWindow(i18n.T("open_in"))
-	win.Resize(fyne.NewSize(400, 300))
+	win.Resize(fyne.NewSize(620, 400))
 	win.CenterOnScreen()
 	win.SetFixedSize(true)
+	// Closing the selector quits, even if a download window is still around
+	win.SetMaster()
+
+	// The filter keeps the keyboard focus, so the selector can be used
+	// without the mouse
+	filterInput := newFilterEntry()
+	filterInput.SetPlaceHolder(i18n.T("filter_slicers"))
 
 	// Create list widget
 	list := widget.NewList(
 		func() int {
-			return len(slicers)
+			mu.Lock()
+			defer mu.Unlock()
+			return len(shown)
 		},
 		func() fyne.CanvasObject {
-			return widget.NewLabel("")
+			return container.NewBorder(nil, nil, widget.NewIcon(nil), nil, widget.NewLabel(""))
 		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			label := obj.(*widget.Label)
-			label.SetText(slicers[id].Name)
+			mu.Lock()
+			if id >= len(shown) {
+				mu.Unlock()
+				return
+			}
+			s := slicers[shown[id]]
+			extensions := unsupported[shown[id]]
+			mu.Unlock()
+
+			var label *widget.Label
+			for _, o := range obj.(*fyne.Container).Objects {
+				switch o := o.(type) {
+				case *widget.Icon:
+					o.SetResource(slicerIcon(s))
+				case *widget.Label:
+					label = o
+				}
+			}
+			if label == nil {
+				return
+			}
+			text := s.Name
+			if id < 9 {
+				// Digits open the first nine slicers
+				text = fmt.Sprintf("%d. %s", id+1, text)
+			}
+			label.TextStyle = fyne.TextStyle{}
+			label.Importance = widget.MediumImportance
+			if len(extensions) > 0 {
+				// Greyed out with the reason
+				label.Importance = widget.LowImportance
+				text += " — " + fmt.Sprintf(i18n.T("cannot_open"), strings.Join(extensions, ", "))
+			} else if s.ID == req.Preferred {
+				label.TextStyle = fyne.TextStyle{Bold: true}
+				if req.PreferredNote != "" {
+					text += " — " + req.PreferredNote
+				}
+			}
+			label.SetText(text)
 		},
 	)
 
-	// Handle selection
+	loadSlicerIcons(slicers, list.Refresh)
+
+	var openBtn *widget.Button
+	cancelCountdown := func() {}
+	selectedItem := -1
+
+	// The countdown and the merging of forwarded files stop when the window
+	// closes
+	done := make(chan struct{})
+	defer close(done)
+
+	// launching is set while req.Launch runs; the buttons keep their state
+	// and forwarded files stay queued until it returns
+	launching := false
+	pauseIncoming := make(chan bool)
+	setIncomingPaused := func(paused bool) {
+		if req.Incoming == nil {
+			return
+		}
+		select {
+		case pauseIncoming <- paused:
+		case <-done:
+		}
+	}
+
+	// Handle selection; slicers that cannot open the files stay selectable
+	// so that their explanation is readable, but cannot be opened
 	list.OnSelected = func(id widget.ListItemID) {
-		if id >= 0 && id < len(slicers) {
-			selectedSlicer = &slicers[id]
+		cancelCountdown()
+		mu.Lock()
+		defer mu.Unlock()
+		if id < 0 || id >= len(shown) {
+			return
+		}
+		selectedItem = id
+		selectedSlicer = &slicers[shown[id]]
+		if openBtn != nil && !launching {
+			if unsupported[shown[id]] != nil {
+				openBtn.Disable()
+			} else {
+				openBtn.Enable()
+			}
 		}
+		// Clicking the list takes the focus from the filter
+		win.Canvas().Focus(filterInput)
 	}
 
-	// Select first item by default
-	if len(slicers) > 0 {
-		list.Select(0)
-		selectedSlicer = &slicers[0]
+	// defaultItem returns the list item to select: the current selection or
+	// the preferred slicer if they can open the files, otherwise the first
+	// slicer that can. It returns -1 if the filter hides all slicers.
+	defaultItem := func() widget.ListItemID {
+		mu.Lock()
+		defer mu.Unlock()
+		first, preferred := -1, -1
+		for id, i := range shown {
+			if unsupported[i] != nil {
+				continue
+			}
+			if selectedSlicer == &slicers[i] {
+				return id
+			}
+			if slicers[i].ID == req.Preferred && preferred < 0 {
+				preferred = id
+			}
+			if first < 0 {
+				first = id
+			}
+		}
+		if preferred >= 0 {
+			return preferred
+		}
+		if first < 0 && len(shown) == 0 {
+			return -1
+		}
+		if first < 0 {
+			return 0
+		}
+		return first
+	}
+
+	// reselect refreshes the list after the listed slicers changed
+	reselect := func() {
+		list.Refresh()
+		item := defaultItem()
+		list.UnselectAll()
+		if item < 0 {
+			mu.Lock()
+			selectedItem = -1
+			selectedSlicer = nil
+			busy := launching
+			mu.Unlock()
+			if openBtn != nil && !busy {
+				openBtn.Disable()
+			}
+			return
+		}
+		list.Select(item)
 	}
 
 	// Create buttons
@@ -63,43 +283,321 @@ func ShowSlicerSelectorWithApp(fyneApp fyne.App, filePath string, slicers []slic
 		fyneApp.Quit()
 	})
 
-	openBtn := widget.NewButton(i18n.T("open"), func() {
-		resultChan <- selectedSlicer
-		win.Close()
-		fyneApp.Quit()
+	// Shown while a launched slicer is being watched for early failures
+	progress := widget.NewProgressBarInfinite()
+	progress.Hide()
+
+	// Offer to remember the choice when the files share an extension or
+	// a directory
+	rememberExtCheck := widget.NewCheck("", nil)
+	rememberDirCheck := widget.NewCheck(i18n.T("remember_directory"), nil)
+	updateRemember := func(files []string) {
+		if ext := rules.CommonExtension(files); ext != "" {
+			rememberExtCheck.SetText(fmt.Sprintf(i18n.T("remember_extension"), "."+ext))
+			rememberExtCheck.Show()
+		} else {
+			rememberExtCheck.SetChecked(false)
+			rememberExtCheck.Hide()
+		}
+		if rules.CommonDirectory(files) != "" {
+			rememberDirCheck.Show()
+		} else {
+			rememberDirCheck.SetChecked(false)
+			rememberDirCheck.Hide()
+		}
+	}
+	updateRemember(files)
+
+	openBtn = widget.NewButton(i18n.T("open"), func() {
+		cancelCountdown()
+		if req.Launch != nil {
+			// Files forwarded from now on are left for the caller, which
+			// opens them in the chosen slicer once Launch returns
+			setIncomingPaused(true)
+		}
+		mu.Lock()
+		launching = req.Launch != nil
+		mu.Unlock()
+		selection := &Selection{Slicer: selectedSlicer, Files: currentFiles()}
+		if rememberExtCheck.Checked {
+			selection.RememberExtension = rules.CommonExtension(selection.Files)
+		}
+		if rememberDirCheck.Checked {
+			selection.RememberDirectory = rules.CommonDirectory(selection.Files)
+		}
+		if req.Launch == nil {
+			resultChan <- selection
+			win.Close()
+			fyneApp.Quit()
+			return
+		}
+
+		openBtn.Disable()
+		cancelBtn.Disable()
+		filterInput.Disable()
+		list.Hide()
+		progress.Show()
+		go func() {
+			err := req.Launch(selection)
+			if err == nil {
+				resultChan <- selection
+				win.Close()
+				fyneApp.Quit()
+				return
+			}
+			mu.Lock()
+			launching = false
+			mu.Unlock()
+			progress.Hide()
+			list.Show()
+			openBtn.Enable()
+			cancelBtn.Enable()
+			filterInput.Enable()
+			setIncomingPaused(false)
+			showLaunchError(win, err)
+		}()
 	})
 	openBtn.Importance = widget.HighImportance
 
+	// Keyboard: typing filters, arrows move, Enter opens, Escape cancels
+	// and 1-9 open the listed slicers directly
+	filterInput.OnChanged = func(text string) {
+		mu.Lock()
+		filter = strings.ToLower(strings.TrimSpace(text))
+		updateSupport()
+		mu.Unlock()
+		reselect()
+	}
+	filterInput.onInteract = func() {
+		cancelCountdown()
+	}
+	filterInput.onKey = func(key fyne.KeyName) bool {
+		switch key {
+		case fyne.KeyReturn, fyne.KeyEnter:
+			if !openBtn.Disabled() {
+				openBtn.OnTapped()
+			}
+		case fyne.KeyEscape:
+			if !cancelBtn.Disabled() {
+				cancelBtn.OnTapped()
+			}
+		case fyne.KeyUp, fyne.KeyDown:
+			mu.Lock()
+			item, count := selectedItem, len(shown)
+			mu.Unlock()
+			if key == fyne.KeyUp && item > 0 {
+				list.Select(item - 1)
+			} else if key == fyne.KeyDown && item < count-1 {
+				list.Select(item + 1)
+			}
+		default:
+			return false
+		}
+		return true
+	}
+	filterInput.onDigit = func(n int) bool {
+		mu.Lock()
+		count := len(shown)
+		mu.Unlock()
+		if n > count {
+			return false
+		}
+		list.Select(n - 1)
+		if !openBtn.Disabled() {
+			openBtn.OnTapped()
+		}
+		return true
+	}
+
+	if item := defaultItem(); item >= 0 {
+		list.Select(item)
+	}
+
 	// Create content with proper layout
 	titleLabel := widget.NewLabel(i18n.T("choose_slicer"))
 	titleLabel.Alignment = fyne.TextAlignCenter
 	titleLabel.TextStyle = fyne.TextStyle{}
 
+	// Files that will be opened
+	filesLabel := widget.NewLabel(describeFiles(files))
+	filesLabel.Alignment = fyne.TextAlignCenter
+	filesLabel.Wrapping = fyne.TextTruncate
+
+	// Count down on the pre-selected slicer like a boot menu; any key press
+	// or click stops it
+	countdownBar := widget.NewProgressBar()
+	countdownBar.Hide()
+	if req.Countdown > 0 {
+		countdownBar.TextFormatter = func() string {
+			mu.Lock()
+			name := ""
+			if selectedSlicer != nil {
+				name = selectedSlicer.Name
+			}
+			mu.Unlock()
+			remaining := int(math.Ceil((1 - countdownBar.Value) * req.Countdown.Seconds()))
+			return fmt.Sprintf(i18n.T("opening_in"), name, remaining)
+		}
+		countdownBar.Show()
+
+		stop := make(chan struct{})
+		var stopOnce sync.Once
+		cancelCountdown = func() {
+			stopOnce.Do(func() {
+				close(stop)
+				countdownBar.Hide()
+			})
+		}
+		if dc, ok := win.Canvas().(desktop.Canvas); ok {
+			dc.SetOnKeyDown(func(*fyne.KeyEvent) {
+				cancelCountdown()
+			})
+		}
+		win.Canvas().SetOnTypedRune(func(rune) {
+			cancelCountdown()
+		})
+
+		start := time.Now()
+		go func() {
+			ticker := time.NewTicker(50 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-stop:
+					return
+				case now := <-ticker.C:
+					elapsed := now.Sub(start)
+					if elapsed < req.Countdown {
+						countdownBar.SetValue(float64(elapsed) / float64(req.Countdown))
+						continue
+					}
+					cancelCountdown()
+					if !openBtn.Disabled() {
+						openBtn.OnTapped()
+					}
+					return
+				}
+			}
+		}()
+	}
+
+	// Preview and details of the first file that can be previewed, or of
+	// the first file; loading a large mesh may take a moment, so the file
+	// name and size are shown until then
+	modelFile := files[0]
+	for _, file := range files {
+		if preview.Supported(file) {
+			modelFile = file
+			break
+		}
+	}
+	modelInfo := widget.NewLabel(describeModel(modelFile, nil))
+	modelInfo.Wrapping = fyne.TextWrapWord
+	modelPane := container.NewVBox(modelInfo)
+	if preview.Supported(modelFile) {
+		previewImage := canvas.NewImageFromResource(theme.FileImageIcon())
+		previewImage.FillMode = canvas.ImageFillContain
+		previewImage.SetMinSize(fyne.NewSize(previewSize, previewSize))
+		modelPane = container.NewVBox(previewImage, modelInfo)
+		go func() {
+			img, m, err := preview.Load(modelFile, 2*previewSize)
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if img != nil {
+				previewImage.Resource = nil
+				previewImage.Image = img
+				previewImage.Refresh()
+			}
+			if err == nil && m != nil {
+				modelInfo.SetText(describeModel(modelFile, m))
+			}
+		}()
+	}
+
+	// Merge files forwarded by other invocations until the window closes,
+	// except while a launch is running
+	if req.Incoming != nil {
+		go func() {
+			source := req.Incoming
+			incoming := source
+			for {
+				select {
+				case <-done:
+					return
+				case paused := <-pauseIncoming:
+					incoming = source
+					if paused {
+						incoming = nil
+					}
+				case paths, ok := <-incoming:
+					if !ok {
+						// Keep answering setIncomingPaused
+						source, incoming = nil, nil
+						continue
+					}
+					// New files need another look from the user
+					cancelCountdown()
+					mu.Lock()
+					files = mergeFiles(files, paths)
+					updateSupport()
+					label := describeFiles(files)
+					merged := append([]string(nil), files...)
+					mu.Unlock()
+					filesLabel.SetText(label)
+					updateRemember(merged)
+					reselect()
+					win.RequestFocus()
+				}
+			}
+		}()
+	}
+
 	// Buttons container - left aligned
 	buttonsContainer := container.NewHBox(
 		cancelBtn,
 		openBtn,
 	)
 
+	// Remember options above the buttons
+	footer := container.NewVBox(countdownBar, rememberExtCheck, rememberDirCheck, buttonsContainer)
+
 	// Main content: Title at top, list in center, buttons at bottom
 	content := container.NewBorder(
-		titleLabel,       // Top
-		buttonsContainer, // Bottom
-		nil, nil,         // Left, Right
-		list, // Center
+		container.NewVBox(titleLabel, filesLabel, filterInput), // Top
+		footer,   // Bottom
+		nil, nil, // Left, Right
+		container.NewStack(list, container.NewCenter(progress)), // Center
 	)
 
-	// Add padding
+	// Add padding; clicks anywhere in the window stop the countdown
 	paddedContent := container.NewBorder(
 		nil, nil, nil, nil,
-		container.NewPadded(content),
+		container.NewStack(
+			newClickCatcher(func() { cancelCountdown() }),
+			container.NewPadded(container.NewBorder(
+				nil, nil, nil,
+				container.NewGridWrap(fyne.NewSize(previewSize, 0), modelPane),
+				content,
+			)),
+		),
 	)
 
 	win.SetContent(paddedContent)
+	win.Canvas().Focus(filterInput)
 	win.Show()
 
-	// Run app event loop (blocking) - this must be called on main thread
-	fyneApp.Run()
+	// Run app event loop (blocking) - this must be called on main thread,
+	// unless RunInBackground already runs it
+	if loopStopped != nil {
+		<-loopStopped
+	} else {
+		fyneApp.Run()
+	}
 
 	// After app.Run() returns, get the result
 	select {
@@ -109,3 +607,138 @@ func ShowSlicerSelectorWithApp(fyneApp fyne.App, filePath string, slicers []slic
 		return nil
 	}
 }
+
+// previewSize is the size of the preview pane in the selector
+const previewSize = 170
+
+// clickCatcher is an invisible background that reports mouse presses which
+// no widget above it handles
+type clickCatcher struct {
+	widget.BaseWidget
+	onMouseDown func()
+}
+
+func newClickCatcher(onMouseDown func()) *clickCatcher {
+	c := &clickCatcher{onMouseDown: onMouseDown}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+func (c *clickCatcher) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(canvas.NewRectangle(color.Transparent))
+}
+
+// MouseDown implements desktop.Mouseable
+func (c *clickCatcher) MouseDown(*desktop.MouseEvent) {
+	c.onMouseDown()
+}
+
+// MouseUp implements desktop.Mouseable
+func (c *clickCatcher) MouseUp(*desktop.MouseEvent) {}
+
+// filterEntry is the selector's filter field. Navigation keys and, while the
+// filter is empty, digits are passed to the selector instead of the entry.
+type filterEntry struct {
+	widget.Entry
+	onInteract func()
+	onKey      func(key fyne.KeyName) bool
+	onDigit    func(n int) bool
+}
+
+func newFilterEntry() *filterEntry {
+	e := &filterEntry{}
+	e.Wrapping = fyne.TextTruncate
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// KeyDown implements desktop.Keyable
+func (e *filterEntry) KeyDown(key *fyne.KeyEvent) {
+	if e.onInteract != nil {
+		e.onInteract()
+	}
+	e.Entry.KeyDown(key)
+}
+
+// MouseDown implements desktop.Mouseable
+func (e *filterEntry) MouseDown(m *desktop.MouseEvent) {
+	if e.onInteract != nil {
+		e.onInteract()
+	}
+	e.Entry.MouseDown(m)
+}
+
+// TypedKey implements fyne.Focusable
+func (e *filterEntry) TypedKey(key *fyne.KeyEvent) {
+	if e.onKey != nil && e.onKey(key.Name) {
+		return
+	}
+	e.Entry.TypedKey(key)
+}
+
+// TypedRune implements fyne.Focusable
+func (e *filterEntry) TypedRune(r rune) {
+	if e.Text == "" && r >= '1' && r <= '9' && e.onDigit != nil && e.onDigit(int(r-'0')) {
+		return
+	}
+	e.Entry.TypedRune(r)
+}
+
+// mergeFiles appends the paths not already present in files
+func mergeFiles(files []string, paths []string) []string {
+	for _, path := range paths {
+		found := false
+		for _, existing := range files {
+			if existing == path {
+				found = true
+				break
+			}
+		}
+		if !found {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// describeFiles returns a short label for the files being opened
+func describeFiles(filePaths []string) string {
+	names := make([]string, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		names = append(names, filepath.Base(filePath))
+	}
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return fmt.Sprintf(i18n.T("n_files"), len(names)) + ": " + strings.Join(names, ", ")
+}
+
+// showLaunchError shows why a slicer failed to start, including its output
+// or the output of the hook that stopped it
+func showLaunchError(win fyne.Window, err error) {
+	showErrorDetails(win, i18n.T("error_launching"), err, nil)
+}
+
+// showErrorDetails shows an error together with the output of the command
+// that failed, if any, and calls onClosed when it is dismissed
+func showErrorDetails(win fyne.Window, title string, err error, onClosed func()) {
+	message := widget.NewLabel(err.Error())
+	message.Wrapping = fyne.TextWrapWord
+	content := []fyne.CanvasObject{message}
+
+	if text := slicer.ErrorOutput(err); text != "" {
+		output := widget.NewLabel(text)
+		output.TextStyle = fyne.TextStyle{Monospace: true}
+		output.Wrapping = fyne.TextWrapBreak
+		scroll := container.NewVScroll(output)
+		scroll.SetMinSize(fyne.NewSize(360, 120))
+		content = append(content, scroll)
+	}
+
+	d := dialog.NewCustom(title, i18n.T("ok"), container.NewVBox(content...), win)
+	if onClosed != nil {
+		d.SetOnClosed(onClosed)
+	}
+	d.Resize(fyne.NewSize(380, 260))
+	d.Show()
+}
@@ -3,18 +3,29 @@ package ui
 import (
 	"fmt"
 	"qslicerpicker/internal/config"
+	"qslicerpicker/internal/history"
 	"qslicerpicker/internal/i18n"
+	"qslicerpicker/internal/icons"
+	"qslicerpicker/internal/shellwords"
 	"qslicerpicker/internal/slicer"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
 var settingsWindow fyne.Window
 
+// reloadSlicerList reloads the list in the current slicers tab
+var reloadSlicerList func()
+
 // ShowSettings shows the settings window
 func ShowSettings() {
 	app := GetApp()
@@ -32,9 +43,27 @@ func ShowSettings() {
 	settingsWindow.SetContent(content)
 	settingsWindow.Show()
 
+	if err := config.LoadError(); err != nil {
+		dialog.ShowError(fmt.Errorf("%s\n%w", i18n.T("config_not_loaded"), err), settingsWindow)
+	}
+
 	settingsWindow.SetOnClosed(func() {
 		settingsWindow = nil
 	})
+
+	if config.GetConfig().SeparateVersions {
+		refreshVersions(reloadSlicerList)
+	}
+}
+
+// refreshVersions looks up the versions only printed by the slicers
+// themselves in the background and calls reload if new ones were found
+func refreshVersions(reload func()) {
+	go func() {
+		if slicer.RefreshVersions() {
+			reload()
+		}
+	}()
 }
 
 func createSettingsContent() fyne.CanvasObject {
@@ -43,6 +72,18 @@ func createSettingsContent() fyne.CanvasObject {
 			Text:    i18n.T("slicers"),
 			Content: createSlicersTab(),
 		},
+		&container.TabItem{
+			Text:    i18n.T("rules"),
+			Content: createRulesTab(),
+		},
+		&container.TabItem{
+			Text:    i18n.T("remembered"),
+			Content: createAssociationsTab(),
+		},
+		&container.TabItem{
+			Text:    i18n.T("launch"),
+			Content: createLaunchTab(),
+		},
 		&container.TabItem{
 			Text:    i18n.T("language"),
 			Content: createLanguageTab(),
@@ -57,30 +98,39 @@ func createSettingsContent() fyne.CanvasObject {
 }
 
 func createSlicersTab() fyne.CanvasObject {
-	allSlicers := slicer.LoadSlicers()
+	// The slicers are replaced from the background when versions are found
+	var slicersMu sync.Mutex
+	listed := slicer.LoadSlicers()
+	currentSlicers := func() []slicer.Slicer {
+		slicersMu.Lock()
+		defer slicersMu.Unlock()
+		return listed
+	}
 
 	// Create list of slicers with checkboxes
 	var list *widget.List
 	list = widget.NewList(
 		func() int {
-			return len(allSlicers)
+			return len(currentSlicers())
 		},
 		func() fyne.CanvasObject {
 			checkbox := widget.NewCheck("", nil)
+			icon := widget.NewIcon(nil)
 			nameLabel := widget.NewLabel("")
 			editBtn := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil)
 			upBtn := widget.NewButtonWithIcon("", theme.MoveUpIcon(), nil)
 			downBtn := widget.NewButtonWithIcon("", theme.MoveDownIcon(), nil)
 
-			// Layout: Checkbox | Name | Spacer | Edit | Up | Down
+			// Layout: Checkbox | Icon | Name | Spacer | Edit | Up | Down
 			return container.NewBorder(
 				nil, nil,
 				checkbox,
 				container.NewHBox(editBtn, upBtn, downBtn),
-				nameLabel,
+				container.NewBorder(nil, nil, icon, nil, nameLabel),
 			)
 		},
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			allSlicers := currentSlicers()
 			if id >= len(allSlicers) {
 				return
 			}
@@ -91,20 +141,31 @@ func createSlicersTab() fyne.CanvasObject {
 			// Find objects
 			var checkbox *widget.Check
 			var buttons *fyne.Container
+			var icon *widget.Icon
 			var nameLabel *widget.Label
 
 			for _, obj := range borderContainer.Objects {
 				if check, ok := obj.(*widget.Check); ok {
 					checkbox = check
-				} else if label, ok := obj.(*widget.Label); ok {
-					nameLabel = label
 				} else if cont, ok := obj.(*fyne.Container); ok {
-					// Buttons container
-					buttons = cont
+					if _, ok := cont.Objects[0].(*widget.Button); ok {
+						// Buttons container
+						buttons = cont
+						continue
+					}
+					// Icon and name
+					for _, obj := range cont.Objects {
+						switch o := obj.(type) {
+						case *widget.Icon:
+							icon = o
+						case *widget.Label:
+							nameLabel = o
+						}
+					}
 				}
 			}
 
-			if checkbox == nil || buttons == nil || nameLabel == nil {
+			if checkbox == nil || buttons == nil || icon == nil || nameLabel == nil {
 				return
 			}
 
@@ -113,11 +174,12 @@ func createSlicersTab() fyne.CanvasObject {
 			downBtn := buttons.Objects[2].(*widget.Button)
 
 			nameLabel.SetText(s.Name)
+			icon.SetResource(slicerIcon(s))
 			checkbox.SetChecked(s.Enabled)
 
 			// Update enabled state
 			checkbox.OnChanged = func(checked bool) {
-				updateSlicerEnabled(s.ID, checked)
+				updateSlicerEnabled(s, checked)
 			}
 
 			// Edit button
@@ -133,19 +195,44 @@ func createSlicersTab() fyne.CanvasObject {
 								cfg.CustomSlicers[i].Name = updatedSlicer.Name
 								cfg.CustomSlicers[i].Path = updatedSlicer.Path
 								cfg.CustomSlicers[i].Arguments = updatedSlicer.Arguments
+								cfg.CustomSlicers[i].ViewerArguments = updatedSlicer.ViewerArguments
+								cfg.CustomSlicers[i].Formats = updatedSlicer.Formats
+								cfg.CustomSlicers[i].Icon = updatedSlicer.Icon
 								cfg.CustomSlicers[i].WorkingDir = updatedSlicer.WorkingDir
+								cfg.CustomSlicers[i].FlatpakID = updatedSlicer.FlatpakID
+								cfg.CustomSlicers[i].SingleFile = updatedSlicer.SingleFile
+								cfg.CustomSlicers[i].Environment = updatedSlicer.Environment
+								cfg.CustomSlicers[i].Wrappers = updatedSlicer.Wrappers
+								cfg.CustomSlicers[i].Hooks = updatedSlicer.Hooks
 								cfg.CustomSlicers[i].Enabled = updatedSlicer.Enabled
 								break
 							}
 						}
 					} else {
-						// Update default slicer config
+						// Update default slicer config. The detected
+						// installation is not pinned, so that it follows
+						// upgrades and separately listed versions.
+						customPath, flatpakID := updatedSlicer.Path, updatedSlicer.FlatpakID
+						if s.ID == s.BaseID() && len(s.Installations) > 0 &&
+							customPath == s.Installations[0].Path && flatpakID == s.Installations[0].FlatpakID {
+							customPath, flatpakID = "", ""
+						}
+						viewerArgs := configOverride(updatedSlicer.ViewerArguments, slicer.DefaultViewerArguments(s.BaseID()))
+						formats := configOverride(updatedSlicer.Formats, slicer.DefaultFormats(s.BaseID()))
 						found := false
 						for i := range cfg.Slicers {
 							if cfg.Slicers[i].ID == updatedSlicer.ID {
-								cfg.Slicers[i].CustomPath = updatedSlicer.Path
+								cfg.Slicers[i].CustomPath = customPath
 								cfg.Slicers[i].Arguments = updatedSlicer.Arguments
+								cfg.Slicers[i].ViewerArguments = viewerArgs
+								cfg.Slicers[i].Formats = formats
+								cfg.Slicers[i].Icon = updatedSlicer.Icon
 								cfg.Slicers[i].WorkingDir = updatedSlicer.WorkingDir
+								cfg.Slicers[i].FlatpakID = flatpakID
+								cfg.Slicers[i].SingleFile = updatedSlicer.SingleFile
+								cfg.Slicers[i].Environment = updatedSlicer.Environment
+								cfg.Slicers[i].Wrappers = updatedSlicer.Wrappers
+								cfg.Slicers[i].Hooks = updatedSlicer.Hooks
 								cfg.Slicers[i].Enabled = updatedSlicer.Enabled
 								found = true
 								break
@@ -154,12 +241,20 @@ func createSlicersTab() fyne.CanvasObject {
 						if !found {
 							// Add if not exists in config override
 							cfg.Slicers = append(cfg.Slicers, config.SlicerConfig{
-								ID:         updatedSlicer.ID,
-								CustomPath: updatedSlicer.Path,
-								Arguments:  updatedSlicer.Arguments,
-								WorkingDir: updatedSlicer.WorkingDir,
-								Enabled:    updatedSlicer.Enabled,
-								Order:      updatedSlicer.Order,
+								ID:              updatedSlicer.ID,
+								CustomPath:      customPath,
+								Arguments:       updatedSlicer.Arguments,
+								ViewerArguments: viewerArgs,
+								Formats:         formats,
+								Icon:            updatedSlicer.Icon,
+								WorkingDir:      updatedSlicer.WorkingDir,
+								FlatpakID:       flatpakID,
+								SingleFile:      updatedSlicer.SingleFile,
+								Environment:     updatedSlicer.Environment,
+								Wrappers:        updatedSlicer.Wrappers,
+								Hooks:           updatedSlicer.Hooks,
+								Enabled:         updatedSlicer.Enabled,
+								Order:           updatedSlicer.Order,
 							})
 						}
 					}
@@ -205,14 +300,90 @@ func createSlicersTab() fyne.CanvasObject {
 		},
 	)
 
+	loadSlicerIcons(listed, list.Refresh)
+	reloadSlicerList = func() {
+		loaded := slicer.LoadSlicers()
+		slicersMu.Lock()
+		listed = loaded
+		slicersMu.Unlock()
+		loadSlicerIcons(loaded, list.Refresh)
+		list.Refresh()
+	}
+
 	// Add custom slicer button
 	addBtn := widget.NewButton(i18n.T("add_custom_slicer"), func() {
 		showAddCustomSlicerDialog()
 	})
+	var addButtons fyne.CanvasObject = addBtn
+	if runtime.GOOS == "linux" {
+		// Installed applications are listed in XDG .desktop entries
+		importBtn := widget.NewButton(i18n.T("import_applications"), func() {
+			showDesktopImportDialog()
+		})
+		addButtons = container.NewGridWithColumns(2, addBtn, importBtn)
+	}
+
+	hideUnsupportedCheck := widget.NewCheck(i18n.T("hide_unsupported"), func(checked bool) {
+		cfg := config.GetConfig()
+		if cfg.HideUnsupportedSlicers != checked {
+			cfg.HideUnsupportedSlicers = checked
+			config.SaveConfig()
+		}
+	})
+	hideUnsupportedCheck.SetChecked(config.GetConfig().HideUnsupportedSlicers)
+
+	separateVersionsCheck := widget.NewCheck(i18n.T("separate_versions"), nil)
+	separateVersionsCheck.SetChecked(config.GetConfig().SeparateVersions)
+	separateVersionsCheck.OnChanged = func(checked bool) {
+		cfg := config.GetConfig()
+		if cfg.SeparateVersions != checked {
+			cfg.SeparateVersions = checked
+			config.SaveConfig()
+			if settingsWindow != nil {
+				settingsWindow.SetContent(createSettingsContent())
+			}
+			if checked {
+				refreshVersions(reloadSlicerList)
+			}
+		}
+	}
+
+	// The selector follows this list or the launch history
+	orderModes := []string{config.OrderManual, config.OrderRecent, config.OrderFrequent}
+	orderSelect := widget.NewSelect([]string{
+		i18n.T("order_manual"),
+		i18n.T("order_recent"),
+		i18n.T("order_frequent"),
+	}, nil)
+	orderSelect.SetSelectedIndex(0)
+	for i, mode := range orderModes {
+		if mode == config.GetConfig().OrderMode {
+			orderSelect.SetSelectedIndex(i)
+		}
+	}
+	orderSelect.OnChanged = func(string) {
+		mode := orderModes[orderSelect.SelectedIndex()]
+		if mode == config.OrderManual {
+			mode = ""
+		}
+		cfg := config.GetConfig()
+		if cfg.OrderMode != mode {
+			cfg.OrderMode = mode
+			config.SaveConfig()
+		}
+	}
+
+	clearHistoryBtn := widget.NewButton(i18n.T("clear_history"), func() {
+		if err := history.Clear(); err != nil {
+			dialog.ShowError(err, settingsWindow)
+		}
+	})
+
+	orderRow := container.NewBorder(nil, nil, widget.NewLabel(i18n.T("selector_order")), clearHistoryBtn, orderSelect)
 
 	return container.NewBorder(
 		nil,
-		addBtn,
+		container.NewVBox(orderRow, hideUnsupportedCheck, separateVersionsCheck, addButtons),
 		nil, nil,
 		list,
 	)
@@ -269,10 +440,10 @@ func createLanguageTab() fyne.CanvasObject {
 	)
 }
 
-func updateSlicerEnabled(id string, enabled bool) {
+func updateSlicerEnabled(s slicer.Slicer, enabled bool) {
 	cfg := config.GetConfig()
 	for i := range cfg.Slicers {
-		if cfg.Slicers[i].ID == id {
+		if cfg.Slicers[i].ID == s.ID {
 			cfg.Slicers[i].Enabled = enabled
 			config.SaveConfig()
 			return
@@ -280,8 +451,9 @@ func updateSlicerEnabled(id string, enabled bool) {
 	}
 	// If not found, add it
 	cfg.Slicers = append(cfg.Slicers, config.SlicerConfig{
-		ID:      id,
+		ID:      s.ID,
 		Enabled: enabled,
+		Order:   s.Order,
 	})
 	config.SaveConfig()
 }
@@ -302,14 +474,25 @@ func moveSlicer(from, to int) {
 	toID := allSlicers[to].ID
 
 	// Find and update in config (for default slicers)
+	foundFrom, foundTo := false, false
 	for i := range cfg.Slicers {
 		if cfg.Slicers[i].ID == fromID {
 			cfg.Slicers[i].Order = toOrder
+			foundFrom = true
 		} else if cfg.Slicers[i].ID == toID {
 			cfg.Slicers[i].Order = fromOrder
+			foundTo = true
 		}
 	}
 
+	// Separately listed versions have no entry until they are changed
+	if !foundFrom && !allSlicers[from].IsCustom {
+		cfg.Slicers = append(cfg.Slicers, config.SlicerConfig{ID: fromID, Enabled: allSlicers[from].Enabled, Order: toOrder})
+	}
+	if !foundTo && !allSlicers[to].IsCustom {
+		cfg.Slicers = append(cfg.Slicers, config.SlicerConfig{ID: toID, Enabled: allSlicers[to].Enabled, Order: fromOrder})
+	}
+
 	// Handle custom slicers
 	if allSlicers[from].IsCustom {
 		// Find custom slicer index
@@ -348,34 +531,75 @@ func showSlicerDialog(s *slicer.Slicer, onSave func(s slicer.Slicer)) {
 	nameEntry := widget.NewEntry()
 	nameEntry.SetPlaceHolder(i18n.T("name"))
 
-	pathEntry := widget.NewEntry()
+	// Offer every discovered installation as a suggestion
+	pathEntry := widget.NewSelectEntry(nil)
 	pathEntry.SetPlaceHolder(i18n.T("path"))
+	if isEdit && len(s.Installations) > 0 {
+		options := make([]string, 0, len(s.Installations))
+		for _, inst := range s.Installations {
+			options = append(options, inst.Path)
+		}
+		pathEntry.SetOptions(options)
+	}
 
 	argsEntry := widget.NewEntry()
-	argsEntry.SetPlaceHolder(i18n.T("arguments"))
+	argsEntry.SetPlaceHolder(i18n.T("arguments_placeholder"))
+
+	viewerArgsEntry := widget.NewEntry()
+	viewerArgsEntry.SetPlaceHolder("--gcodeviewer")
+
+	formatsEntry := widget.NewEntry()
+	formatsEntry.SetPlaceHolder("stl, 3mf, obj")
 
 	workingDirEntry := widget.NewEntry()
 	workingDirEntry.SetPlaceHolder(i18n.T("working_directory"))
 
+	iconEntry := widget.NewEntry()
+	iconEntry.SetPlaceHolder(i18n.T("icon_placeholder"))
+
+	flatpakEntry := widget.NewEntry()
+	flatpakEntry.SetPlaceHolder("com.prusa3d.PrusaSlicer")
+
+	envEntry := widget.NewMultiLineEntry()
+	envEntry.SetPlaceHolder("GDK_BACKEND=x11\nLD_LIBRARY_PATH+=/opt/lib\n-QT_SCALE_FACTOR")
+	envEntry.SetMinRowsVisible(3)
+
+	wrappersEntry := widget.NewMultiLineEntry()
+	wrappersEntry.SetPlaceHolder("distrobox enter printing --\nfirejail --whitelist={dir}")
+	wrappersEntry.SetMinRowsVisible(2)
+
+	preLaunchEntry := widget.NewMultiLineEntry()
+	preLaunchEntry.SetPlaceHolder("~/bin/copy-to-project.sh")
+	preLaunchEntry.SetMinRowsVisible(2)
+
+	postExitEntry := widget.NewMultiLineEntry()
+	postExitEntry.SetPlaceHolder("~/bin/upload-gcode.sh {dir}")
+	postExitEntry.SetMinRowsVisible(2)
+
 	enabledCheck := widget.NewCheck(i18n.T("enabled"), nil)
 	enabledCheck.SetChecked(true)
 
+	singleFileCheck := widget.NewCheck(i18n.T("single_file"), nil)
+
 	if isEdit {
 		nameEntry.SetText(s.Name)
 		pathEntry.SetText(s.Path)
 		if len(s.Arguments) > 0 {
-			// Join arguments
-			argsStr := ""
-			for i, arg := range s.Arguments {
-				if i > 0 {
-					argsStr += " "
-				}
-				argsStr += arg
-			}
-			argsEntry.SetText(argsStr)
+			argsEntry.SetText(shellwords.Join(s.Arguments))
 		}
+		if len(s.ViewerArguments) > 0 {
+			viewerArgsEntry.SetText(shellwords.Join(s.ViewerArguments))
+		}
+		formatsEntry.SetText(strings.Join(s.Formats, ", "))
 		workingDirEntry.SetText(s.WorkingDir)
+		iconEntry.SetText(s.Icon)
+		flatpakEntry.SetText(s.FlatpakID)
+		envEntry.SetText(slicer.FormatEnvironment(s.Environment))
+		wrappersEntry.SetText(slicer.FormatCommands(s.Wrappers))
+		preLaunchEntry.SetText(slicer.FormatCommands(s.Hooks.PreLaunch))
+		postExitEntry.SetText(slicer.FormatCommands(s.Hooks.PostExit))
 		enabledCheck.SetChecked(s.Enabled)
+		singleFileCheck.SetChecked(s.SingleFile)
 
 		// If not custom, disable name editing
 		if !s.IsCustom {
@@ -402,20 +626,94 @@ func showSlicerDialog(s *slicer.Slicer, onSave func(s slicer.Slicer)) {
 		}, settingsWindow)
 	})
 
+	browseIconBtn := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			reader.Close()
+			iconEntry.SetText(reader.URI().Path())
+		}, settingsWindow)
+		fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".png", ".jpg", ".jpeg", ".svg"}))
+		fileDialog.Show()
+	})
+
+	// Default slicers can go back to their built-in viewer arguments and
+	// formats
+	viewerArgsItem, formatsItem := fyne.CanvasObject(viewerArgsEntry), fyne.CanvasObject(formatsEntry)
+	if isEdit && !s.IsCustom {
+		viewerArgsItem = withResetButton(viewerArgsEntry, shellwords.Join(slicer.DefaultViewerArguments(s.BaseID())))
+		formatsItem = withResetButton(formatsEntry, strings.Join(slicer.DefaultFormats(s.BaseID()), ", "))
+	}
+
 	// Dialog instance
 	var d dialog.Dialog
 
 	saveBtn := widget.NewButton(i18n.T("save"), func() {
-		if nameEntry.Text == "" || pathEntry.Text == "" {
+		// Flatpak slicers have no host path
+		if nameEntry.Text == "" || (pathEntry.Text == "" && flatpakEntry.Text == "") {
+			return
+		}
+
+		args, err := shellwords.Split(argsEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("arguments"), err), settingsWindow)
+			return
+		}
+
+		viewerArgs, err := shellwords.Split(viewerArgsEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("viewer_arguments"), err), settingsWindow)
+			return
+		}
+
+		env, err := slicer.ParseEnvironment(envEntry.Text)
+		if err == nil && strings.TrimSpace(flatpakEntry.Text) != "" {
+			err = slicer.CheckFlatpakEnvironment(env)
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("environment"), err), settingsWindow)
 			return
 		}
 
+		wrappers, err := slicer.ParseCommands(wrappersEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("wrappers"), err), settingsWindow)
+			return
+		}
+
+		preLaunch, err := slicer.ParseCommands(preLaunchEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("pre_launch_hooks"), err), settingsWindow)
+			return
+		}
+
+		postExit, err := slicer.ParseCommands(postExitEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("post_exit_hooks"), err), settingsWindow)
+			return
+		}
+
+		iconPath := strings.TrimSpace(iconEntry.Text)
+		if iconPath != "" {
+			if _, err := icons.FromFile(iconPath); err != nil {
+				dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("icon"), err), settingsWindow)
+				return
+			}
+		}
+
 		newSlicer := slicer.Slicer{
-			Name:       nameEntry.Text,
-			Path:       pathEntry.Text,
-			WorkingDir: workingDirEntry.Text,
-			Enabled:    enabledCheck.Checked,
-			IsCustom:   true, // Default to true, logic will handle override
+			Name:        nameEntry.Text,
+			Path:        pathEntry.Text,
+			WorkingDir:  workingDirEntry.Text,
+			Icon:        iconPath,
+			FlatpakID:   flatpakEntry.Text,
+			SingleFile:  singleFileCheck.Checked,
+			Environment: env,
+			Wrappers:    wrappers,
+			Hooks:       config.Hooks{PreLaunch: preLaunch, PostExit: postExit},
+			Enabled:     enabledCheck.Checked,
+			IsCustom:    true, // Default to true, logic will handle override
 		}
 
 		if isEdit {
@@ -424,12 +722,9 @@ func showSlicerDialog(s *slicer.Slicer, onSave func(s slicer.Slicer)) {
 			newSlicer.IsCustom = s.IsCustom
 		}
 
-		if argsEntry.Text != "" {
-			// Simple split (TODO: better argument parsing)
-			newSlicer.Arguments = []string{argsEntry.Text}
-		} else {
-			newSlicer.Arguments = []string{}
-		}
+		newSlicer.Arguments = args
+		newSlicer.ViewerArguments = viewerArgs
+		newSlicer.Formats = parseExtensions(formatsEntry.Text)
 
 		onSave(newSlicer)
 		d.Hide()
@@ -441,14 +736,24 @@ func showSlicerDialog(s *slicer.Slicer, onSave func(s slicer.Slicer)) {
 			widget.NewFormItem(i18n.T("name"), nameEntry),
 			widget.NewFormItem(i18n.T("path"), container.NewBorder(nil, nil, nil, browsePathBtn, pathEntry)),
 			widget.NewFormItem(i18n.T("arguments"), argsEntry),
+			widget.NewFormItem(i18n.T("viewer_arguments"), viewerArgsItem),
+			widget.NewFormItem(i18n.T("formats"), formatsItem),
 			widget.NewFormItem(i18n.T("working_directory"), container.NewBorder(nil, nil, nil, browseDirBtn, workingDirEntry)),
+			widget.NewFormItem(i18n.T("icon"), container.NewBorder(nil, nil, nil, browseIconBtn, iconEntry)),
+			widget.NewFormItem(i18n.T("flatpak_app_id"), flatpakEntry),
+			widget.NewFormItem(i18n.T("environment"), envEntry),
+			widget.NewFormItem(i18n.T("wrappers"), wrappersEntry),
+			widget.NewFormItem(i18n.T("pre_launch_hooks"), preLaunchEntry),
+			widget.NewFormItem(i18n.T("post_exit_hooks"), postExitEntry),
 		),
 		enabledCheck,
+		singleFileCheck,
 		saveBtn, // Only save button, dismiss button is handled by dialog
 	)
 
-	d = dialog.NewCustom(title, i18n.T("cancel"), content, settingsWindow)
-	d.Resize(fyne.NewSize(500, 400))
+	// The form outgrows small screens, keep it scrollable
+	d = dialog.NewCustom(title, i18n.T("cancel"), container.NewVScroll(content), settingsWindow)
+	d.Resize(fyne.NewSize(600, 600))
 	d.Show()
 }
 
@@ -456,12 +761,20 @@ func showAddCustomSlicerDialog() {
 	cfg := config.GetConfig()
 	showSlicerDialog(nil, func(s slicer.Slicer) {
 		customSlicer := config.CustomSlicer{
-			Name:       s.Name,
-			Path:       s.Path,
-			Arguments:  s.Arguments,
-			WorkingDir: s.WorkingDir,
-			Enabled:    s.Enabled,
-			Order:      len(cfg.CustomSlicers) * 10,
+			Name:            s.Name,
+			Path:            s.Path,
+			Arguments:       s.Arguments,
+			ViewerArguments: s.ViewerArguments,
+			Formats:         s.Formats,
+			Icon:            s.Icon,
+			WorkingDir:      s.WorkingDir,
+			FlatpakID:       s.FlatpakID,
+			SingleFile:      s.SingleFile,
+			Environment:     s.Environment,
+			Wrappers:        s.Wrappers,
+			Hooks:           s.Hooks,
+			Enabled:         s.Enabled,
+			Order:           len(cfg.CustomSlicers) * 10,
 		}
 		cfg.CustomSlicers = append(cfg.CustomSlicers, customSlicer)
 		config.SaveConfig()
@@ -471,3 +784,39 @@ func showAddCustomSlicerDialog() {
 		}
 	})
 }
+
+// withResetButton adds a button that puts the defaults back into the entry
+func withResetButton(entry *widget.Entry, defaults string) fyne.CanvasObject {
+	if defaults == "" {
+		return entry
+	}
+	resetBtn := widget.NewButtonWithIcon("", theme.ContentUndoIcon(), func() {
+		entry.SetText(defaults)
+	})
+	return container.NewBorder(nil, nil, nil, resetBtn, entry)
+}
+
+// configOverride returns what the config stores for a setting with built-in
+// defaults: nil while it matches them, so that it keeps following them, and
+// an empty list when it was cleared
+func configOverride(values, defaults []string) []string {
+	if slices.Equal(values, defaults) {
+		return nil
+	}
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// parseExtensions splits a list such as "stl, .3mf obj" into extensions
+// without the dot
+func parseExtensions(text string) []string {
+	var extensions []string
+	for _, ext := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		extensions = append(extensions, strings.ToLower(strings.TrimPrefix(ext, ".")))
+	}
+	return extensions
+}
@@ -1,18 +1,35 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
+	"qslicerpicker/internal/config"
 	"qslicerpicker/internal/filehandler"
 	"qslicerpicker/internal/ui"
 )
 
 func main() {
-	// Check if a file path is provided as argument
-	if len(os.Args) > 1 {
-		filePath := os.Args[1]
-		// Show selector dialog and handle file
-		filehandler.HandleFile(filePath)
+	// The defaults are used instead; the file is left alone so it can be fixed
+	if err := config.LoadError(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	// --pick shows the selector even if a slicer is remembered for the files
+	var opts filehandler.Options
+	args := make([]string, 0, len(os.Args))
+	for _, arg := range os.Args[1:] {
+		if arg == "--pick" {
+			opts.Pick = true
+			continue
+		}
+		args = append(args, arg)
+	}
+
+	// Check if file paths or slicer URLs are provided as arguments
+	if len(args) > 0 {
+		// Show selector dialog and handle all files at once
+		filehandler.HandleFileWithOptions(opts, args...)
 		return
 	}
 